@@ -0,0 +1,268 @@
+// Package spotifyid parses and formats the identifiers of Spotify tracks,
+// albums, artists and playlists.
+//
+// An identifier can be written as a bare base62 id such as
+// "4ry6oqlwdsooYtniYJFkt5", as a URI such as "spotify:track:4ry6oqlwdsooYtniYJFkt5",
+// or as a link such as "https://open.spotify.com/track/4ry6oqlwdsooYtniYJFkt5?si=abc".
+// The Parse functions accept all of these and return the bare id, typed by
+// what it identifies.
+package spotifyid
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// idLength is the number of base62 characters in a Spotify id.
+const idLength = 22
+
+// webBaseUrl is where the Spotify web player shows what an id identifies.
+const webBaseUrl = "https://open.spotify.com/"
+
+// embedBaseUrl is where the Spotify embeddable player plays what an id identifies.
+const embedBaseUrl = webBaseUrl + "embed/"
+
+const (
+	trackKind    = "track"
+	albumKind    = "album"
+	artistKind   = "artist"
+	playlistKind = "playlist"
+)
+
+// ID is implemented by TrackID, AlbumID, ArtistID and PlaylistID.
+type ID interface {
+	// String returns the bare id.
+	String() string
+	// URI returns the id as a Spotify URI, such as "spotify:track:4ry6oqlwdsooYtniYJFkt5".
+	URI() string
+	// URL returns the link to the id in the Spotify web player.
+	URL() string
+	// EmbedURL returns the link to the id in the Spotify embeddable player.
+	EmbedURL() string
+}
+
+// TrackID is the bare base62 id of a track. Converting a string to a TrackID
+// does not check it, so ids from users or other services should be parsed
+// with ParseTrackID, which also takes URIs and links.
+type TrackID string
+
+// AlbumID is the bare base62 id of an album. It is parsed with ParseAlbumID.
+type AlbumID string
+
+// ArtistID is the bare base62 id of an artist. It is parsed with ParseArtistID.
+type ArtistID string
+
+// PlaylistID is the bare base62 id of a playlist. It is parsed with
+// ParsePlaylistID.
+type PlaylistID string
+
+// ParseError is returned when a string can not be parsed as an id. Input is
+// the string that was parsed.
+type ParseError struct {
+	Input string
+	Msg   string
+}
+
+func (pe ParseError) Error() string {
+	return pe.Msg
+}
+
+// ParseTrackID returns the track id in s, which is either a bare id, a track
+// URI or a link to a track. A URI or link to anything else is an error.
+func ParseTrackID(s string) (TrackID, error) {
+	id, err := parseKind(trackKind, s)
+
+	return TrackID(id), err
+}
+
+// ParseAlbumID returns the album id in s, which is either a bare id, an album
+// URI or a link to an album. A URI or link to anything else is an error.
+func ParseAlbumID(s string) (AlbumID, error) {
+	id, err := parseKind(albumKind, s)
+
+	return AlbumID(id), err
+}
+
+// ParseArtistID returns the artist id in s, which is either a bare id, an
+// artist URI or a link to an artist. A URI or link to anything else is an error.
+func ParseArtistID(s string) (ArtistID, error) {
+	id, err := parseKind(artistKind, s)
+
+	return ArtistID(id), err
+}
+
+// ParsePlaylistID returns the playlist id in s, which is either a bare id, a
+// playlist URI or a link to a playlist. A URI or link to anything else is an error.
+func ParsePlaylistID(s string) (PlaylistID, error) {
+	id, err := parseKind(playlistKind, s)
+
+	return PlaylistID(id), err
+}
+
+// Parse returns the id in the URI or link s, typed by what it identifies.
+// A bare id is an error, since it does not tell what it identifies.
+func Parse(s string) (ID, error) {
+	kind, id, err := parse(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case trackKind:
+		return TrackID(id), nil
+	case albumKind:
+		return AlbumID(id), nil
+	case artistKind:
+		return ArtistID(id), nil
+	case playlistKind:
+		return PlaylistID(id), nil
+	}
+
+	return nil, ParseError{Input: s, Msg: fmt.Sprintf("%q does not tell whether it is a track, album, artist or playlist id.", s)}
+}
+
+// Valid tells whether id is a bare base62 id, as returned by ParseTrackID.
+func (id TrackID) Valid() bool { return isBase62Id(string(id)) }
+
+// Valid tells whether id is a bare base62 id, as returned by ParseAlbumID.
+func (id AlbumID) Valid() bool { return isBase62Id(string(id)) }
+
+// Valid tells whether id is a bare base62 id, as returned by ParseArtistID.
+func (id ArtistID) Valid() bool { return isBase62Id(string(id)) }
+
+// Valid tells whether id is a bare base62 id, as returned by ParsePlaylistID.
+func (id PlaylistID) Valid() bool { return isBase62Id(string(id)) }
+
+func (id TrackID) String() string   { return string(id) }
+func (id TrackID) URI() string      { return uri(trackKind, string(id)) }
+func (id TrackID) URL() string      { return webBaseUrl + trackKind + "/" + string(id) }
+func (id TrackID) EmbedURL() string { return embedBaseUrl + trackKind + "/" + string(id) }
+
+func (id AlbumID) String() string   { return string(id) }
+func (id AlbumID) URI() string      { return uri(albumKind, string(id)) }
+func (id AlbumID) URL() string      { return webBaseUrl + albumKind + "/" + string(id) }
+func (id AlbumID) EmbedURL() string { return embedBaseUrl + albumKind + "/" + string(id) }
+
+func (id ArtistID) String() string   { return string(id) }
+func (id ArtistID) URI() string      { return uri(artistKind, string(id)) }
+func (id ArtistID) URL() string      { return webBaseUrl + artistKind + "/" + string(id) }
+func (id ArtistID) EmbedURL() string { return embedBaseUrl + artistKind + "/" + string(id) }
+
+func (id PlaylistID) String() string   { return string(id) }
+func (id PlaylistID) URI() string      { return uri(playlistKind, string(id)) }
+func (id PlaylistID) URL() string      { return webBaseUrl + playlistKind + "/" + string(id) }
+func (id PlaylistID) EmbedURL() string { return embedBaseUrl + playlistKind + "/" + string(id) }
+
+func uri(kind, id string) string {
+	return "spotify:" + kind + ":" + id
+}
+
+// parseKind returns the id in s, making sure that it identifies kind if s
+// tells what it identifies.
+func parseKind(kind, s string) (string, error) {
+	foundKind, id, err := parse(s)
+
+	if err != nil {
+		return "", err
+	}
+
+	if foundKind != "" && foundKind != kind {
+		return "", ParseError{Input: s, Msg: fmt.Sprintf("%q has the kind %s, but a %s id was expected.", s, foundKind, kind)}
+	}
+
+	return id, nil
+}
+
+// parse splits s into what it identifies and its id. The kind is empty for
+// a bare id.
+func parse(s string) (kind, id string, err error) {
+	trimmed := strings.TrimSpace(s)
+
+	switch {
+	case strings.HasPrefix(trimmed, "spotify:"):
+		kind, id = parseUri(trimmed)
+	case strings.Contains(trimmed, "spotify.com/"):
+		kind, id = parseUrl(trimmed)
+	default:
+		id = trimmed
+	}
+
+	if kind == "" && id != trimmed {
+		return "", "", ParseError{Input: s, Msg: fmt.Sprintf("%q is not a Spotify URI or link.", s)}
+	}
+
+	if kind != "" && kind != trackKind && kind != albumKind && kind != artistKind && kind != playlistKind {
+		return "", "", ParseError{Input: s, Msg: fmt.Sprintf("%q has the unsupported kind %s.", s, kind)}
+	}
+
+	if !isBase62Id(id) {
+		return "", "", ParseError{Input: s, Msg: fmt.Sprintf("%q does not hold a valid id of %d base62 characters.", s, idLength)}
+	}
+
+	return kind, id, nil
+}
+
+// parseUri splits a URI such as "spotify:track:4ry6oqlwdsooYtniYJFkt5", or
+// the older form of playlist URIs "spotify:user:name:playlist:37i9dQZF1DXcBWIGoYBM5M".
+func parseUri(uri string) (kind, id string) {
+	parts := strings.Split(uri, ":")
+
+	switch {
+	case len(parts) == 3:
+		return parts[1], parts[2]
+	case len(parts) == 5 && parts[1] == "user" && parts[3] == playlistKind:
+		return parts[3], parts[4]
+	}
+
+	return "", ""
+}
+
+// parseUrl splits a link such as "https://open.spotify.com/track/4ry6oqlwdsooYtniYJFkt5?si=abc".
+// Links localized with a path prefix such as "intl-de", embed links and older
+// playlist links under a user are also accepted.
+func parseUrl(link string) (kind, id string) {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+
+	if err != nil || (u.Host != "open.spotify.com" && u.Host != "play.spotify.com") {
+		return "", ""
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+		segments = segments[1:]
+	}
+
+	if len(segments) > 0 && segments[0] == "embed" {
+		segments = segments[1:]
+	}
+
+	switch {
+	case len(segments) == 2:
+		return segments[0], segments[1]
+	case len(segments) == 4 && segments[0] == "user" && segments[2] == playlistKind:
+		return segments[2], segments[3]
+	}
+
+	return "", ""
+}
+
+func isBase62Id(id string) bool {
+	if len(id) != idLength {
+		return false
+	}
+
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
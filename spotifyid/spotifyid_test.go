@@ -0,0 +1,141 @@
+package spotifyid
+
+import (
+	"testing"
+)
+
+const trackId = "4ry6oqlwdsooYtniYJFkt5"
+
+func TestParseTrackID(t *testing.T) {
+	inputs := []string{
+		trackId,
+		" " + trackId + "\n",
+		"spotify:track:" + trackId,
+		"https://open.spotify.com/track/" + trackId,
+		"https://open.spotify.com/track/" + trackId + "?si=a1b2c3",
+		"open.spotify.com/track/" + trackId,
+		"https://open.spotify.com/intl-de/track/" + trackId,
+		"https://open.spotify.com/embed/track/" + trackId,
+		"http://play.spotify.com/track/" + trackId + "/",
+	}
+
+	for _, input := range inputs {
+		actual, err := ParseTrackID(input)
+
+		if err != nil {
+			t.Errorf("Expected error for %q to be nil. Got: %s", input, err.Error())
+		}
+
+		if actual != trackId {
+			t.Errorf("Unexpected id parsed from %q.\nExpected: %s\nActual:   %s", input, trackId, actual)
+		}
+	}
+}
+
+func TestParseTrackIDInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"4ry6oqlwdsooYtniYJFkt",
+		"4ry6oqlwdsooYtniYJFkt5a",
+		"4ry6oqlwdsooYtniYJFk-5",
+		"spotify:track:",
+		"spotify:album:" + trackId,
+		"spotify:episode:" + trackId,
+		"spotify:track:" + trackId + ":extra",
+		"https://open.spotify.com/album/" + trackId,
+		"https://example.com/track/" + trackId,
+		"https://open.spotify.com/track",
+	}
+
+	for _, input := range inputs {
+		if _, err := ParseTrackID(input); err == nil {
+			t.Errorf("Expected an error parsing %q.", input)
+		} else if _, ok := err.(ParseError); !ok {
+			t.Errorf("Expected error to be a ParseError. Got: %T", err)
+		}
+	}
+}
+
+func TestParsePlaylistID(t *testing.T) {
+	const playlistId = "37i9dQZF1DXcBWIGoYBM5M"
+
+	inputs := []string{
+		"spotify:playlist:" + playlistId,
+		"spotify:user:spotify:playlist:" + playlistId,
+		"https://open.spotify.com/user/spotify/playlist/" + playlistId + "?si=x",
+	}
+
+	for _, input := range inputs {
+		if actual, err := ParsePlaylistID(input); err != nil || actual != playlistId {
+			t.Errorf("Unexpected result of parsing %q: %s, %v", input, actual, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected ID
+	}{
+		{"spotify:track:" + trackId, TrackID(trackId)},
+		{"https://open.spotify.com/album/" + trackId, AlbumID(trackId)},
+		{"spotify:artist:" + trackId, ArtistID(trackId)},
+		{"https://open.spotify.com/playlist/" + trackId, PlaylistID(trackId)},
+	}
+
+	for _, c := range cases {
+		if actual, err := Parse(c.input); err != nil || actual != c.expected {
+			t.Errorf("Unexpected result of parsing %q.\nExpected: %#v\nActual:   %#v, %v", c.input, c.expected, actual, err)
+		}
+	}
+
+	if _, err := Parse(trackId); err == nil {
+		t.Error("Expected an error parsing a bare id.")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		id                 ID
+		uri, url, embedUrl string
+	}{
+		{TrackID(trackId), "spotify:track:" + trackId, "https://open.spotify.com/track/" + trackId, "https://open.spotify.com/embed/track/" + trackId},
+		{AlbumID(trackId), "spotify:album:" + trackId, "https://open.spotify.com/album/" + trackId, "https://open.spotify.com/embed/album/" + trackId},
+		{ArtistID(trackId), "spotify:artist:" + trackId, "https://open.spotify.com/artist/" + trackId, "https://open.spotify.com/embed/artist/" + trackId},
+		{PlaylistID(trackId), "spotify:playlist:" + trackId, "https://open.spotify.com/playlist/" + trackId, "https://open.spotify.com/embed/playlist/" + trackId},
+	}
+
+	for _, c := range cases {
+		if c.id.String() != trackId {
+			t.Errorf("Unexpected String().\nExpected: %s\nActual:   %s", trackId, c.id.String())
+		}
+
+		if c.id.URI() != c.uri {
+			t.Errorf("Unexpected URI().\nExpected: %s\nActual:   %s", c.uri, c.id.URI())
+		}
+
+		if c.id.URL() != c.url {
+			t.Errorf("Unexpected URL().\nExpected: %s\nActual:   %s", c.url, c.id.URL())
+		}
+
+		if c.id.EmbedURL() != c.embedUrl {
+			t.Errorf("Unexpected EmbedURL().\nExpected: %s\nActual:   %s", c.embedUrl, c.id.EmbedURL())
+		}
+
+		if parsed, err := Parse(c.id.URL()); err != nil || parsed != c.id {
+			t.Errorf("Expected %s to parse back to the id. Got: %v, %v", c.id.URL(), parsed, err)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	if !TrackID(trackId).Valid() || !AlbumID(trackId).Valid() || !ArtistID(trackId).Valid() || !PlaylistID(trackId).Valid() {
+		t.Error("Expected a bare base62 id to be valid.")
+	}
+
+	for _, id := range []TrackID{"", "abc", TrackID("spotify:track:" + trackId), TrackID(trackId + ",")} {
+		if id.Valid() {
+			t.Errorf("Expected %q not to be valid.", id)
+		}
+	}
+}
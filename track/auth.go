@@ -0,0 +1,191 @@
+package track
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultTokenUrl = "https://accounts.spotify.com/api/token"
+
+// tokenRefreshMargin is how long before it expires an access token is
+// replaced, so that it does not expire while a request is on its way. Tokens
+// living shorter than ten times the margin are replaced when a tenth of their
+// lifetime is left instead, so that they are not fetched again for every request.
+const tokenRefreshMargin = time.Minute
+
+// tokenSource fetches access tokens using the client credentials flow
+// (https://developer.spotify.com/documentation/web-api/tutorials/client-credentials-flow)
+// and caches them until they are about to expire. It is safe for
+// concurrent use, and it is shared by all copies of the Searcher it
+// belongs to.
+type tokenSource struct {
+	clientId     string
+	clientSecret string
+	tokenUrl     string
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+	lifetime    time.Duration
+	fetch       *tokenFetch
+	now         func() time.Time
+}
+
+// tokenFetch is a request to the token endpoint in flight. Done is closed when
+// it has finished, after which accessToken or err is set.
+type tokenFetch struct {
+	done        chan struct{}
+	accessToken string
+	err         error
+}
+
+func newTokenSource(clientId, clientSecret string) *tokenSource {
+	return &tokenSource{
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		now:          time.Now,
+	}
+}
+
+// tokenResponse is used for unmarshalling the json data from the token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// token returns the cached access token, or fetches a new one with client if
+// there is none or it is about to expire. Concurrent callers share a single
+// request to the token endpoint, but each of them stops waiting for it when
+// its own ctx is done. If the request was given up because the context of the
+// caller sending it was done, the callers waiting for it send a new one.
+func (ts *tokenSource) token(ctx context.Context, client *http.Client) (string, error) {
+	for {
+		ts.mu.Lock()
+
+		if ts.accessToken != "" && ts.now().Add(min(tokenRefreshMargin, ts.lifetime/10)).Before(ts.expiry) {
+			accessToken := ts.accessToken
+			ts.mu.Unlock()
+
+			return accessToken, nil
+		}
+
+		if ts.fetch == nil {
+			fetch := &tokenFetch{done: make(chan struct{})}
+			ts.fetch = fetch
+			ts.mu.Unlock()
+
+			return ts.fetchToken(ctx, client, fetch)
+		}
+
+		fetch := ts.fetch
+		ts.mu.Unlock()
+
+		select {
+		case <-fetch.done:
+		case <-ctx.Done():
+			return "", contextError(ctx)
+		}
+
+		if terr, isTrackError := fetch.err.(TrackError); !isTrackError || terr.ErrorType != CanceledError {
+			return fetch.accessToken, fetch.err
+		}
+	}
+}
+
+// fetchToken requests a new access token for fetch, caches it and lets the
+// callers waiting for fetch know.
+func (ts *tokenSource) fetchToken(ctx context.Context, client *http.Client, fetch *tokenFetch) (string, error) {
+	accessToken, lifetime, err := ts.requestToken(ctx, client)
+
+	ts.mu.Lock()
+
+	if err == nil {
+		ts.accessToken = accessToken
+		ts.lifetime = lifetime
+		ts.expiry = ts.now().Add(lifetime)
+	}
+
+	ts.fetch = nil
+	ts.mu.Unlock()
+
+	fetch.accessToken, fetch.err = accessToken, err
+	close(fetch.done)
+
+	return accessToken, err
+}
+
+// requestToken sends a request for a new access token to the token endpoint,
+// and returns the token and how long it is valid. A response with a server
+// error or a rate limit gives a TrackError with the StatusCode of the response,
+// so that the request it was needed for is retried like a failed API request.
+func (ts *tokenSource) requestToken(ctx context.Context, client *http.Client) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, ts.tokenUrl, strings.NewReader(form.Encode()))
+
+	if reqErr != nil {
+		return "", 0, TrackError{Msg: "Unable to create request for access token.", ErrorType: UnexpectedError, OriginalError: reqErr}
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(ts.clientId, ts.clientSecret)
+
+	resp, httpErr := client.Do(req)
+
+	if httpErr != nil {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return "", 0, ctxErr
+		}
+
+		return "", 0, TrackError{Msg: "Request for access token failed.", ErrorType: UnexpectedError, OriginalError: httpErr, Url: ts.tokenUrl}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("Request for access token returned status %d rather than %d.", resp.StatusCode, http.StatusOK)
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return "", 0, TrackError{Msg: msg, ErrorType: RateLimitError, StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), Url: ts.tokenUrl}
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return "", 0, TrackError{Msg: msg, ErrorType: ExternalServiceError, StatusCode: resp.StatusCode, Url: ts.tokenUrl}
+		}
+
+		return "", 0, TrackError{Msg: msg, ErrorType: AuthenticationError, StatusCode: resp.StatusCode, Url: ts.tokenUrl}
+	}
+
+	body, ioutilErr := ioutil.ReadAll(resp.Body)
+
+	if ioutilErr != nil {
+		return "", 0, TrackError{Msg: "ioutil.ReadAll failed reading access token.", ErrorType: UnexpectedError, OriginalError: ioutilErr}
+	}
+
+	var tr tokenResponse
+
+	if err := json.Unmarshal(body, &tr); err != nil || tr.AccessToken == "" {
+		return "", 0, TrackError{Msg: "Unable to unmarshal access token.", ErrorType: AuthenticationError, OriginalError: err}
+	}
+
+	return tr.AccessToken, time.Duration(tr.ExpiresIn) * time.Second, nil
+}
+
+// invalidate drops the cached access token if it is still accessToken, so
+// that the next call to token fetches a new one. A token that has already
+// been replaced by another goroutine is left alone.
+func (ts *tokenSource) invalidate(accessToken string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.accessToken == accessToken {
+		ts.accessToken = ""
+	}
+}
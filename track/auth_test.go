@@ -0,0 +1,293 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTokenServer returns a server handing out the access tokens "token1",
+// "token2" and so on, valid for expiresIn, to the client "id" with secret
+// "secret".
+func newTokenServer(expiresIn string, requests *int) *httptest.Server {
+	var mu sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		id, secret, ok := r.BasicAuth()
+
+		if !ok || id != "id" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			http.Error(w, `{"error": "invalid_client"}`, http.StatusBadRequest)
+			return
+		}
+
+		*requests++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "token` + string(rune('0'+*requests)) + `", "token_type": "Bearer", "expires_in": ` + expiresIn + `}`))
+	}))
+}
+
+func TestClientCredentialsTokenIsCached(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	var authorizations []string
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer api.Close()
+
+	s := newMockSearcher(api.URL, WithClientCredentials("id", "secret"), WithTokenURL(tokenServer.URL))
+
+	s.Find("Human Behaviour", "Björk", "")
+	s.Find("Human Behaviour", "Björk", "")
+
+	if tokenRequests != 1 {
+		t.Errorf("Expected a single token request. Got: %d", tokenRequests)
+	}
+
+	for _, authorization := range authorizations {
+		if authorization != "Bearer token1" {
+			t.Errorf("Unexpected Authorization header: %s", authorization)
+		}
+	}
+}
+
+func TestClientCredentialsTokenIsRefreshedBeforeExpiry(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	ts := newTokenSource("id", "secret")
+	ts.tokenUrl = tokenServer.URL
+
+	now := time.Now()
+	ts.now = func() time.Time { return now }
+
+	first, _ := ts.token(context.Background(), http.DefaultClient)
+
+	now = now.Add(time.Hour - tokenRefreshMargin/2)
+
+	second, _ := ts.token(context.Background(), http.DefaultClient)
+
+	if first != "token1" || second != "token2" {
+		t.Errorf("Expected token to be refreshed. Got: %s and %s", first, second)
+	}
+}
+
+func TestClientCredentialsRetriesOnceOnUnauthorized(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token2" {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer api.Close()
+
+	s := newMockSearcher(api.URL, WithClientCredentials("id", "secret"), WithTokenURL(tokenServer.URL))
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if err != nil {
+		t.Errorf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if tokenRequests != 2 {
+		t.Errorf("Expected 2 token requests. Got: %d", tokenRequests)
+	}
+}
+
+func TestClientCredentialsUnauthorizedTwiceReturnsAuthenticationError(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	apiRequests := 0
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiRequests++
+		http.Error(w, "", http.StatusUnauthorized)
+	}))
+	defer api.Close()
+
+	s := newMockSearcher(api.URL, WithClientCredentials("id", "secret"), WithTokenURL(tokenServer.URL))
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != AuthenticationError {
+		t.Errorf("Expected a TrackError with ErrorType AuthenticationError. Got: %v", err)
+	}
+
+	if apiRequests != 2 {
+		t.Errorf("Expected 2 api requests. Got: %d", apiRequests)
+	}
+}
+
+func TestClientCredentialsInvalidClientReturnsAuthenticationError(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	s := newMockSearcher("http://127.0.0.1:1", WithClientCredentials("id", "wrong"), WithTokenURL(tokenServer.URL))
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != AuthenticationError {
+		t.Errorf("Expected a TrackError with ErrorType AuthenticationError. Got: %v", err)
+	}
+}
+
+func TestClientCredentialsConcurrentUse(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer api.Close()
+
+	s := newMockSearcher(api.URL, WithClientCredentials("id", "secret"), WithTokenURL(tokenServer.URL))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.Find("Human Behaviour", "Björk", "")
+		}()
+	}
+
+	wg.Wait()
+
+	if tokenRequests != 1 {
+		t.Errorf("Expected a single token request. Got: %d", tokenRequests)
+	}
+}
+
+func TestClientCredentialsShortLivedTokenIsCached(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("30", &tokenRequests)
+	defer tokenServer.Close()
+
+	ts := newTokenSource("id", "secret")
+	ts.tokenUrl = tokenServer.URL
+
+	now := time.Now()
+	ts.now = func() time.Time { return now }
+
+	first, _ := ts.token(context.Background(), http.DefaultClient)
+
+	now = now.Add(20 * time.Second)
+
+	second, _ := ts.token(context.Background(), http.DefaultClient)
+
+	now = now.Add(8 * time.Second)
+
+	third, _ := ts.token(context.Background(), http.DefaultClient)
+
+	if first != "token1" || second != "token1" || third != "token2" {
+		t.Errorf("Expected token to be kept until a tenth of its lifetime is left. Got: %s, %s and %s", first, second, third)
+	}
+}
+
+func TestClientCredentialsWaitingCallerStopsOnItsContext(t *testing.T) {
+	release := make(chan struct{})
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		tokenServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer slow.Close()
+
+	ts := newTokenSource("id", "secret")
+	ts.tokenUrl = slow.URL
+
+	first := make(chan string)
+
+	go func() {
+		token, _ := ts.token(context.Background(), http.DefaultClient)
+		first <- token
+	}()
+
+	for {
+		ts.mu.Lock()
+		fetching := ts.fetch != nil
+		ts.mu.Unlock()
+
+		if fetching {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := ts.token(ctx, http.DefaultClient)
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != CanceledError {
+		t.Errorf("Expected a TrackError with ErrorType CanceledError. Got: %v", err)
+	}
+
+	close(release)
+
+	if token := <-first; token != "token1" {
+		t.Errorf("Expected the first caller to get token1. Got: %s", token)
+	}
+}
+
+func TestClientCredentialsTokenServerErrorIsRetried(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := newTokenServer("3600", &tokenRequests)
+	defer tokenServer.Close()
+
+	failures := 0
+
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures == 0 {
+			failures++
+			http.Error(w, "", http.StatusServiceUnavailable)
+			return
+		}
+
+		tokenServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer api.Close()
+
+	s := newMockSearcher(api.URL, WithClientCredentials("id", "secret"), WithTokenURL(flaky.URL), WithRetry(2, time.Millisecond))
+
+	if _, err := s.Find("Human Behaviour", "Björk", ""); err != nil {
+		t.Errorf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if failures != 1 || tokenRequests != 1 {
+		t.Errorf("Expected the token request to be retried once. Got: %d failures and %d tokens", failures, tokenRequests)
+	}
+}
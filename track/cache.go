@@ -0,0 +1,229 @@
+package track
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheSize is the number of responses kept by the LRUCache created
+// when WithCache is given a nil cache.
+const defaultCacheSize = 4096
+
+// defaultCacheTTL is how long a cached response is used without asking
+// Spotify whether it has changed.
+const defaultCacheTTL = time.Hour
+
+// defaultNegativeCacheTTL is how long a cached search response without
+// any tracks is used without asking Spotify again. It is shorter than
+// defaultCacheTTL since new tracks are added all the time.
+const defaultNegativeCacheTTL = 10 * time.Minute
+
+// Cache stores responses from the Spotify Web API, keyed by the url of the
+// request. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+}
+
+// CacheEntry is a cached response. Body is the body of the response and ETag
+// its ETag header, which is used to ask Spotify whether the response has changed
+// once it expires.
+type CacheEntry struct {
+	Body    []byte
+	ETag    string
+	Expires time.Time
+}
+
+// fetchCachedData returns the cached body of the response to a GET request for
+// url if it has not expired. Otherwise the request is sent, conditional on the
+// ETag of the expired response if there is one, and the cache is updated.
+func (s Searcher) fetchCachedData(ctx context.Context, url string) ([]byte, error) {
+	entry, found := s.cache.Get(url)
+	now := time.Now()
+
+	if found && now.Before(entry.Expires) {
+		s.logger().DebugContext(ctx, "cache hit", "url", url)
+		return entry.Body, nil
+	}
+
+	etag := ""
+
+	if found {
+		etag = entry.ETag
+	}
+
+	resp, err := s.fetchResponse(ctx, url, etag)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.notModified {
+		if !found {
+			return resp.body, nil
+		}
+
+		s.logger().DebugContext(ctx, "cache revalidated", "url", url)
+	} else {
+		entry = CacheEntry{Body: resp.body, ETag: resp.etag}
+	}
+
+	ttl := s.cacheTTL
+
+	if isEmptyResult(entry.Body) {
+		ttl = s.negativeCacheTTL
+	}
+
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl)
+		s.cache.Set(url, entry)
+	}
+
+	return entry.Body, nil
+}
+
+// matchCacheKey returns the key a track found by Find for title, artist and album
+// in market is cached under. Case and extra whitespace do not change the key.
+func matchCacheKey(title, artist, album, market string) string {
+	fields := []string{"match", title, artist, album, market}
+
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.Join(strings.Fields(field), " "))
+	}
+
+	return strings.Join(fields, "\x00")
+}
+
+// matchKey returns the key the track found by Find for title, artist and album
+// with fo is cached under. The names are normalized by the Normalizer of the
+// Searcher first, so that names sending the same search share the key.
+func (s Searcher) matchKey(title, artist, album string, fo findOptions) string {
+	title, artist, album = s.normalizeQuery(title, artist, album)
+
+	return matchCacheKey(title, artist, album, s.market) + fo.cacheKey()
+}
+
+// cachedMatch returns the track cached under key in the match cache of the
+// Searcher, if there is one that has not expired.
+func (s Searcher) cachedMatch(key string) (Track, bool) {
+	if s.matchCache == nil {
+		return Track{}, false
+	}
+
+	entry, found := s.matchCache.Get(key)
+
+	if !found || !time.Now().Before(entry.Expires) {
+		return Track{}, false
+	}
+
+	var track Track
+
+	if json.Unmarshal(entry.Body, &track) != nil {
+		return Track{}, false
+	}
+
+	return track, true
+}
+
+// cacheMatch stores track under key in the match cache of the Searcher, if it
+// has one.
+func (s Searcher) cacheMatch(key string, track Track) {
+	if s.matchCache == nil || s.cacheTTL <= 0 {
+		return
+	}
+
+	body, err := json.Marshal(track)
+
+	if err != nil {
+		return
+	}
+
+	s.matchCache.Set(key, CacheEntry{Body: body, Expires: time.Now().Add(s.cacheTTL)})
+}
+
+// isEmptyResult tells whether body is a search response without any tracks.
+func isEmptyResult(body []byte) bool {
+	var result struct {
+		Tracks *trackItem
+	}
+
+	if json.Unmarshal(body, &result) != nil || result.Tracks == nil {
+		return false
+	}
+
+	return len(result.Tracks.Items) == 0
+}
+
+// LRUCache is an in-memory Cache holding a fixed number of entries. When it is
+// full, the least recently used entry is evicted to make room for a new one.
+type LRUCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries *list.List
+	keys    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewLRUCache returns an empty LRUCache holding up to capacity entries.
+// A capacity below one is treated as one.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(1, capacity),
+		entries:  list.New(),
+		keys:     make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key, if any, and marks it as recently used.
+func (c *LRUCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.keys[key]
+
+	if !found {
+		return CacheEntry{}, false
+	}
+
+	c.entries.MoveToFront(element)
+
+	return element.Value.(*lruEntry).entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entry if the
+// cache is full.
+func (c *LRUCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.keys[key]; found {
+		element.Value.(*lruEntry).entry = entry
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.keys[key] = c.entries.PushFront(&lruEntry{key: key, entry: entry})
+
+	if c.entries.Len() > c.capacity {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.keys, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
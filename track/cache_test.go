@@ -0,0 +1,197 @@
+package track
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// expireAll makes every entry of c expire.
+func expireAll(c *LRUCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.entries.Front(); element != nil; element = element.Next() {
+		element.Value.(*lruEntry).entry.Expires = time.Time{}
+	}
+}
+
+func TestCacheServesRepeatedRequests(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithCache(nil))
+
+	for i := 0; i < 3; i++ {
+		track, err := s.Find("Human Behaviour", "Björk", "")
+
+		if err != nil || track.Uri != "spotify:track:4ry6oqlwdsooYtniYJFkt5" {
+			t.Errorf("Unexpected result of cached Find: %v, %v", track, err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
+
+func TestCacheRevalidatesWithETag(t *testing.T) {
+	requests := 0
+	var ifNoneMatch string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		ifNoneMatch = r.Header.Get("If-None-Match")
+
+		if ifNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	s := newMockSearcher(ts.URL, WithCache(cache))
+
+	s.Find("Human Behaviour", "Björk", "")
+	expireAll(cache)
+
+	track, err := s.Find("Human Behaviour", "Björk", "")
+
+	if err != nil || track.Uri != "spotify:track:4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Unexpected result of revalidated Find: %v, %v", track, err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests. Got: %d", requests)
+	}
+
+	if ifNoneMatch != `"v1"` {
+		t.Errorf("Expected If-None-Match to be the cached ETag. Got: %s", ifNoneMatch)
+	}
+
+	s.Find("Human Behaviour", "Björk", "")
+
+	if requests != 2 {
+		t.Errorf("Expected revalidated response to be cached again. Got %d requests", requests)
+	}
+}
+
+func TestCacheUsesNegativeTTLForEmptyResults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	s := newMockSearcher(ts.URL, WithCache(cache), WithCacheTTL(time.Hour, time.Minute))
+
+	before := time.Now()
+	s.Find("Human Behaviour", "Björk", "")
+
+	entry, found := cache.Get(s.searchUrl("track%3A%22Human+Behaviour%22+artist%3A%22Bj%C3%B6rk%22", 1))
+
+	if !found {
+		t.Fatal("Expected empty result to be cached.")
+	}
+
+	if entry.Expires.After(before.Add(2 * time.Minute)) {
+		t.Errorf("Expected empty result to expire within a minute. Expires: %v", entry.Expires)
+	}
+}
+
+func TestCacheDoesNotCacheErrors(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	s := newMockSearcher(ts.URL, WithCache(cache))
+
+	s.Find("Human Behaviour", "Björk", "")
+	s.Find("Human Behaviour", "Björk", "")
+
+	if requests != 2 || cache.Len() != 0 {
+		t.Errorf("Expected failed responses not to be cached. Got %d requests and %d entries", requests, cache.Len())
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+
+	c.Set("a", CacheEntry{ETag: "a"})
+	c.Set("b", CacheEntry{ETag: "b"})
+	c.Get("a")
+	c.Set("c", CacheEntry{ETag: "c"})
+
+	if _, found := c.Get("b"); found {
+		t.Error("Expected b to be evicted.")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if entry, found := c.Get(key); !found || entry.ETag != key {
+			t.Errorf("Expected %s to be cached. Got: %v, %v", key, entry, found)
+		}
+	}
+
+	c.Set("a", CacheEntry{ETag: "a2"})
+
+	if entry, _ := c.Get("a"); entry.ETag != "a2" || c.Len() != 2 {
+		t.Errorf("Expected a to be replaced. Got: %v, %d entries", entry, c.Len())
+	}
+}
+
+func TestLRUCacheConcurrentUse(t *testing.T) {
+	c := NewLRUCache(8)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				key := string(rune('a' + (i+j)%16))
+				c.Set(key, CacheEntry{ETag: key})
+				c.Get(key)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if c.Len() != 8 {
+		t.Errorf("Expected cache to be full. Got %d entries", c.Len())
+	}
+}
+
+func TestIsEmptyResult(t *testing.T) {
+	cases := map[string]bool{
+		noTracksJSON:                       true,
+		oneTrackJSON:                       false,
+		`{"id": "4ry6oqlwdsooYtniYJFkt5"}`: false,
+		`not json`:                         false,
+	}
+
+	for body, expected := range cases {
+		if actual := isEmptyResult([]byte(body)); expected != actual {
+			t.Errorf("Unexpected result of isEmptyResult(%s). Expected: %v, got: %v", body, expected, actual)
+		}
+	}
+}
@@ -17,7 +17,7 @@ func TestConstructSearchURLAllArgumentsIncluded(t *testing.T) {
 		url.QueryEscape(fmt.Sprintf("track:\"%s\" artist:\"%s\"", title, artist)),
 		url.QueryEscape(fmt.Sprintf("track:\"%s\" album:\"%s\"", title, album)),
 	}
-	actual, err := constructSearchQuery(title, artist, album)
+	actual, _, err := constructSearchQuery(title, artist, album)
 
 	if err != nil {
 		t.Errorf("Expected error to be nil. Got: %s", err.Error())
@@ -33,7 +33,7 @@ func TestConstructSearchURLEmptyTitleReturnsError(t *testing.T) {
 	artist := "qwer"
 	album := "ty"
 
-	_, err := constructSearchQuery(title, artist, album)
+	_, _, err := constructSearchQuery(title, artist, album)
 
 	if err == nil {
 		t.Error("Passing empty title should return error.")
@@ -45,7 +45,7 @@ func TestConstructSearchURLEmptyTitleCheckErrorMessage(t *testing.T) {
 	artist := "qwer"
 	album := "ty"
 
-	_, err := constructSearchQuery(title, artist, album)
+	_, _, err := constructSearchQuery(title, artist, album)
 
 	expected := "github.com/joarleth/spotify/track: A title and at least one of article and album must be passed as arguments."
 	actual := err.Error()
@@ -60,7 +60,7 @@ func TestConstructSearchURLEmptyTitleReturnsTrackErrorArgumentError(t *testing.T
 	artist := "qwer"
 	album := "ty"
 
-	_, err := constructSearchQuery(title, artist, album)
+	_, _, err := constructSearchQuery(title, artist, album)
 
 	terr, isTrackError := err.(TrackError)
 
@@ -78,7 +78,7 @@ func TestConstructSearchURLEmptyTitleReturnsNil(t *testing.T) {
 	artist := "qwer"
 	album := "ty"
 
-	actual, _ := constructSearchQuery(title, artist, album)
+	actual, _, _ := constructSearchQuery(title, artist, album)
 
 	if actual != nil {
 		t.Errorf("Expected constructSearchQuery to return nil.\nActual return value: %v", actual)
@@ -90,7 +90,7 @@ func TestConstructSearchURLOnlyTitleNotEmptyReturnsError(t *testing.T) {
 	artist := ""
 	album := ""
 
-	_, err := constructSearchQuery(title, artist, album)
+	_, _, err := constructSearchQuery(title, artist, album)
 
 	if err == nil {
 		t.Error("Passing empty artist and album should return error.")
@@ -102,7 +102,7 @@ func TestConstructSearchURLOnlyTitleCheckErrorMessage(t *testing.T) {
 	artist := ""
 	album := ""
 
-	_, err := constructSearchQuery(title, artist, album)
+	_, _, err := constructSearchQuery(title, artist, album)
 
 	expected := "github.com/joarleth/spotify/track: A title and at least one of article and album must be passed as arguments."
 	actual := err.Error()
@@ -117,7 +117,7 @@ func TestConstructSearchURLOnlyTitleReturnsNil(t *testing.T) {
 	artist := ""
 	album := ""
 
-	actual, _ := constructSearchQuery(title, artist, album)
+	actual, _, _ := constructSearchQuery(title, artist, album)
 
 	if actual != nil {
 		t.Errorf("Expected constructSearchQuery to return nil.\nActual return value: %v", actual)
@@ -130,7 +130,7 @@ func TestConstructSearchURLEmptyArtistOmitsArtistFromSearchString(t *testing.T)
 	album := "ty"
 
 	expected := []string{url.QueryEscape(fmt.Sprintf("track:\"%s\" album:\"%s\"", title, album))}
-	actual, err := constructSearchQuery(title, artist, album)
+	actual, _, err := constructSearchQuery(title, artist, album)
 
 	if err != nil {
 		t.Errorf("Expected error to be nil. Got: %s", err.Error())
@@ -147,7 +147,7 @@ func TestConstructSearchURLEmptyAlbumOmitsAlbumFromSearchString(t *testing.T) {
 	album := ""
 
 	expected := []string{url.QueryEscape(fmt.Sprintf("track:\"%s\" artist:\"%s\"", title, artist))}
-	actual, err := constructSearchQuery(title, artist, album)
+	actual, _, err := constructSearchQuery(title, artist, album)
 
 	if err != nil {
 		t.Errorf("Expected error to be nil. Got: %s", err.Error())
@@ -164,7 +164,7 @@ func TestConstructSearchURLStringsAreTrimmed(t *testing.T) {
 	album := "  ty"
 
 	expected := []string{url.QueryEscape(fmt.Sprintf("track:\"%s\" album:\"%s\"", "asdf", "ty"))}
-	actual, err := constructSearchQuery(title, artist, album)
+	actual, _, err := constructSearchQuery(title, artist, album)
 
 	if err != nil {
 		t.Errorf("Expected error to be nil. Got: %s", err.Error())
@@ -0,0 +1,211 @@
+package track
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskCacheSuffix is the file name suffix of the entries of a DiskCache.
+const diskCacheSuffix = ".json"
+
+// diskCacheSweepInterval is how often a DiskCache sweeps itself while entries
+// are being added to it.
+const diskCacheSweepInterval = time.Minute
+
+// diskCacheRevalidationGrace is how long a DiskCache keeps an expired entry
+// with an ETag, so that the Searcher can still ask Spotify whether the response
+// has changed rather than fetch it again.
+const diskCacheRevalidationGrace = 7 * 24 * time.Hour
+
+// DiskCache is a Cache keeping its entries as files in a directory, so that they
+// survive across runs. It can hold both the responses cached by WithCache and the
+// tracks cached by WithMatchCache. Entries are written to a temporary file that is renamed
+// into place, which makes the cache safe to share between several processes:
+// a reader sees either the old or the new entry, never a half written one.
+//
+// The cache sweeps itself in the background every diskCacheSweepInterval while
+// entries are being added, removing expired entries and, when the entries take
+// up more than maxBytes, the least recently used ones. Expired entries with an
+// ETag are kept for diskCacheRevalidationGrace, unless the size limit is
+// reached, since their ETag is used to revalidate them.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	sweeping  bool
+	sweeps    sync.WaitGroup
+	now       func() time.Time
+}
+
+// diskCacheEntry is the content of an entry file. The key is kept so that
+// a hash collision is not mistaken for a hit.
+type diskCacheEntry struct {
+	Key     string
+	Body    []byte
+	ETag    string
+	Expires time.Time
+}
+
+// NewDiskCache returns a DiskCache keeping its entries in dir, which is created
+// if it does not exist. A maxBytes that is not positive means no size limit.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, TrackError{Msg: "Unable to create cache directory.", ErrorType: UnexpectedError, OriginalError: err}
+	}
+
+	return &DiskCache{
+		dir:       dir,
+		maxBytes:  maxBytes,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+diskCacheSuffix)
+}
+
+// Get returns the entry stored under key, if any, and marks it as recently used.
+func (c *DiskCache) Get(key string) (CacheEntry, bool) {
+	path := c.path(key)
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	var entry diskCacheEntry
+
+	if json.Unmarshal(data, &entry) != nil || entry.Key != key {
+		return CacheEntry{}, false
+	}
+
+	now := c.now()
+	os.Chtimes(path, now, now)
+
+	return CacheEntry{Body: entry.Body, ETag: entry.ETag, Expires: entry.Expires}, true
+}
+
+// Set stores entry under key. Errors writing the entry are ignored, since a
+// failed write only means that the response is fetched again next time.
+func (c *DiskCache) Set(key string, entry CacheEntry) {
+	data, err := json.Marshal(diskCacheEntry{Key: key, Body: entry.Body, ETag: entry.ETag, Expires: entry.Expires})
+
+	if err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+
+	if err != nil {
+		return
+	}
+
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), c.path(key)) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sweeping || c.now().Sub(c.lastSweep) < diskCacheSweepInterval {
+		return
+	}
+
+	c.sweeping = true
+	c.sweeps.Add(1)
+
+	go func() {
+		defer c.sweeps.Done()
+
+		c.Sweep()
+
+		c.mu.Lock()
+		c.sweeping = false
+		c.mu.Unlock()
+	}()
+}
+
+// Sweep removes the expired entries of the cache, except the ones with an ETag
+// expired less than diskCacheRevalidationGrace ago, and then the least recently
+// used entries until the rest fit within the size limit. Entries removed by
+// another process at the same time are skipped.
+func (c *DiskCache) Sweep() error {
+	c.mu.Lock()
+	c.lastSweep = c.now()
+	c.mu.Unlock()
+
+	files, err := ioutil.ReadDir(c.dir)
+
+	if err != nil {
+		return TrackError{Msg: "Unable to read cache directory.", ErrorType: UnexpectedError, OriginalError: err}
+	}
+
+	now := c.now()
+	var kept []os.FileInfo
+	var size int64
+
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), "tmp-") && now.Sub(file.ModTime()) > diskCacheSweepInterval {
+			// Left behind by a process that died while writing an entry.
+			os.Remove(filepath.Join(c.dir, file.Name()))
+			continue
+		}
+
+		if file.IsDir() || !strings.HasSuffix(file.Name(), diskCacheSuffix) {
+			continue
+		}
+
+		path := filepath.Join(c.dir, file.Name())
+		data, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			continue
+		}
+
+		var entry diskCacheEntry
+
+		if json.Unmarshal(data, &entry) != nil || !now.Before(entry.Expires) && (entry.ETag == "" || !now.Before(entry.Expires.Add(diskCacheRevalidationGrace))) {
+			os.Remove(path)
+			continue
+		}
+
+		kept = append(kept, file)
+		size += file.Size()
+	}
+
+	if c.maxBytes <= 0 || size <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].ModTime().Before(kept[j].ModTime())
+	})
+
+	for _, file := range kept {
+		if size <= c.maxBytes {
+			break
+		}
+
+		os.Remove(filepath.Join(c.dir, file.Name()))
+		size -= file.Size()
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package track
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheSurvivesNewInstance(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewDiskCache(dir, 0)
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	expected := CacheEntry{Body: []byte(oneTrackJSON), ETag: `"v1"`, Expires: time.Now().Add(time.Hour).Round(0)}
+	first.Set("key", expected)
+
+	second, _ := NewDiskCache(dir, 0)
+	actual, found := second.Get("key")
+
+	if !found {
+		t.Fatal("Expected entry to be found by another instance.")
+	}
+
+	if !bytes.Equal(expected.Body, actual.Body) || expected.ETag != actual.ETag || !expected.Expires.Equal(actual.Expires) {
+		t.Errorf("Unexpected entry.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+
+	if _, found := second.Get("other key"); found {
+		t.Error("Expected other key not to be found.")
+	}
+}
+
+func TestDiskCacheSweepRemovesExpiredEntries(t *testing.T) {
+	c, _ := NewDiskCache(t.TempDir(), 0)
+
+	c.Set("expired", CacheEntry{Body: []byte("a"), Expires: time.Now().Add(-time.Minute)})
+	c.Set("fresh", CacheEntry{Body: []byte("b"), Expires: time.Now().Add(time.Hour)})
+
+	if err := c.Sweep(); err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if _, found := c.Get("expired"); found {
+		t.Error("Expected expired entry to be swept.")
+	}
+
+	if _, found := c.Get("fresh"); !found {
+		t.Error("Expected fresh entry to be kept.")
+	}
+}
+
+func TestDiskCacheSweepKeepsExpiredEntriesWithETag(t *testing.T) {
+	c, _ := NewDiskCache(t.TempDir(), 0)
+
+	c.Set("revalidatable", CacheEntry{Body: []byte("a"), ETag: `"v1"`, Expires: time.Now().Add(-time.Hour)})
+	c.Set("too old", CacheEntry{Body: []byte("b"), ETag: `"v1"`, Expires: time.Now().Add(-diskCacheRevalidationGrace - time.Hour)})
+
+	c.Sweep()
+
+	if _, found := c.Get("revalidatable"); !found {
+		t.Error("Expected expired entry with an ETag to be kept for revalidation.")
+	}
+
+	if _, found := c.Get("too old"); found {
+		t.Error("Expected entry expired longer than the grace period to be swept.")
+	}
+}
+
+func TestDiskCacheRevalidatesAfterSweep(t *testing.T) {
+	requests := 0
+	revalidations := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	c, _ := NewDiskCache(t.TempDir(), 0)
+	s := newMockSearcher(ts.URL, WithCache(c), WithCacheTTL(time.Nanosecond, time.Nanosecond))
+
+	s.Find("Human Behaviour", "Björk", "")
+	time.Sleep(time.Millisecond)
+	c.Sweep()
+
+	track, err := s.Find("Human Behaviour", "Björk", "")
+
+	if err != nil || track.Uri != "spotify:track:4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Unexpected result of Find: %v, %v", track, err)
+	}
+
+	if requests != 2 || revalidations != 1 {
+		t.Errorf("Expected the second request to revalidate. Got %d requests and %d revalidations", requests, revalidations)
+	}
+}
+
+func TestDiskCacheSetSweepsInBackground(t *testing.T) {
+	c, _ := NewDiskCache(t.TempDir(), 0)
+
+	c.Set("expired", CacheEntry{Body: []byte("a"), Expires: time.Now().Add(-time.Minute)})
+
+	c.mu.Lock()
+	c.lastSweep = time.Now().Add(-diskCacheSweepInterval)
+	c.mu.Unlock()
+
+	c.Set("fresh", CacheEntry{Body: []byte("b"), Expires: time.Now().Add(time.Hour)})
+	c.sweeps.Wait()
+
+	if _, found := c.Get("expired"); found {
+		t.Error("Expected expired entry to be swept.")
+	}
+
+	if _, found := c.Get("fresh"); !found {
+		t.Error("Expected fresh entry to be kept.")
+	}
+}
+
+func TestDiskCacheSweepKeepsSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := NewDiskCache(dir, 0)
+	expires := time.Now().Add(time.Hour)
+
+	for i, key := range []string{"a", "b", "c"} {
+		c.Set(key, CacheEntry{Body: bytes.Repeat([]byte("x"), 100), Expires: expires})
+
+		mtime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(c.path(key), mtime, mtime)
+	}
+
+	info, _ := os.Stat(c.path("a"))
+	c.maxBytes = 2 * info.Size()
+
+	c.Sweep()
+
+	if _, found := c.Get("a"); found {
+		t.Error("Expected least recently used entry to be swept.")
+	}
+
+	for _, key := range []string{"b", "c"} {
+		if _, found := c.Get(key); !found {
+			t.Errorf("Expected %s to be kept.", key)
+		}
+	}
+}
+
+func TestDiskCacheSweepRemovesStaleTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := NewDiskCache(dir, 0)
+
+	stale := filepath.Join(dir, "tmp-123")
+	ioutil.WriteFile(stale, []byte("{"), 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(stale, old, old)
+
+	c.Sweep()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Expected stale temporary file to be removed.")
+	}
+}
+
+func TestMatchCacheSkipsSearch(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	c, _ := NewDiskCache(dir, 0)
+
+	s := newMockSearcher(ts.URL, WithMatchCache(c), WithMarket("SE"))
+	s.Find("Human Behaviour", "Björk", "")
+
+	// A new Searcher and DiskCache, as in a restarted process.
+	c, _ = NewDiskCache(dir, 0)
+	s = newMockSearcher(ts.URL, WithMatchCache(c), WithMarket("SE"))
+
+	track, err := s.Find("  human behaviour", "BJÖRK ", "")
+
+	if err != nil || track.Uri != "spotify:track:4ry6oqlwdsooYtniYJFkt5" || track.QueryLevel != TitleArtistQuery {
+		t.Errorf("Unexpected result of Find: %v, %v", track, err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+
+	s = newMockSearcher(ts.URL, WithMatchCache(c), WithMarket("US"))
+	s.Find("Human Behaviour", "Björk", "")
+
+	if requests != 2 {
+		t.Errorf("Expected another market to miss the cache. Got %d requests", requests)
+	}
+}
+
+func TestMatchCacheKey(t *testing.T) {
+	expected := matchCacheKey("Human Behaviour", "Björk", "", "SE")
+	actual := matchCacheKey(" human   BEHAVIOUR ", "björk", " ", "se")
+
+	if expected != actual {
+		t.Errorf("Expected keys to be equal.\nExpected: %q\nActual:   %q", expected, actual)
+	}
+
+	if matchCacheKey("Human Behaviour", "Björk", "", "") == matchCacheKey("Human Behaviour", "", "Björk", "") {
+		t.Error("Expected artist and album to give different keys.")
+	}
+}
+
+func TestMatchKeyUsesNormalizer(t *testing.T) {
+	s := NewSearcher(WithNormalizer(DefaultNormalizer), WithMarket("SE"))
+
+	if s.matchKey("Song (2011 Remaster)", "Björk", "", findOptions{}) != s.matchKey("Song", "Bjork", "", findOptions{}) {
+		t.Error("Expected names normalizing alike to give the same key.")
+	}
+
+	if plain := NewSearcher(WithMarket("SE")); plain.matchKey("Song", "Björk", "", findOptions{}) == plain.matchKey("Song", "Bjork", "", findOptions{}) {
+		t.Error("Expected names to be kept apart without a Normalizer.")
+	}
+}
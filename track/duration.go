@@ -0,0 +1,100 @@
+package track
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultDurationTolerance is how far the duration of a track may be from the
+// expected one when no tolerance is given, allowing for the differences in
+// length between a file and the same recording on Spotify.
+const defaultDurationTolerance = 2 * time.Second
+
+// FindOption tunes a single call to Find, FindClosestMatch or their Context
+// variants.
+type FindOption func(*findOptions)
+
+// findOptions holds the FindOptions of a call. A zero duration means that no
+// duration is expected.
+type findOptions struct {
+	duration        time.Duration
+	tolerance       time.Duration
+	requireDuration bool
+}
+
+// PreferDuration makes Find and FindClosestMatch prefer tracks lasting within
+// tolerance of duration. Among the results of a search query, the tracks within
+// that window are picked from if there are any, and the others otherwise.
+// FindClosestMatch also counts the difference in duration into the confidence
+// of a match, and tells it in Match.DurationDelta. A tolerance that is not
+// positive means defaultDurationTolerance.
+func PreferDuration(duration, tolerance time.Duration) FindOption {
+	return func(o *findOptions) {
+		o.duration = duration
+		o.tolerance = tolerance
+		o.requireDuration = false
+
+		if o.tolerance <= 0 {
+			o.tolerance = defaultDurationTolerance
+		}
+	}
+}
+
+// RequireDuration is like PreferDuration, but tracks outside the window are
+// never returned. When a search query returns none within it, the next query
+// is tried, and if none of them does, a TrackError with ErrorType NotFoundError
+// is returned.
+func RequireDuration(duration, tolerance time.Duration) FindOption {
+	return func(o *findOptions) {
+		PreferDuration(duration, tolerance)(o)
+		o.requireDuration = true
+	}
+}
+
+func newFindOptions(opts []FindOption) findOptions {
+	var o findOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// within tells whether a track lasting duration is within the expected window.
+// Every duration is when no duration is expected.
+func (o findOptions) within(duration time.Duration) bool {
+	return o.duration <= 0 || (duration-o.duration).Abs() <= o.tolerance
+}
+
+// filter returns the items within the expected window, if there are any.
+// Otherwise it returns no items if the duration is required, and all of them
+// if it is only preferred.
+func (o findOptions) filter(items []item) []item {
+	if o.duration <= 0 {
+		return items
+	}
+
+	var within []item
+
+	for _, candidate := range items {
+		if o.within(time.Duration(candidate.DurationMs) * time.Millisecond) {
+			within = append(within, candidate)
+		}
+	}
+
+	if len(within) > 0 || o.requireDuration {
+		return within
+	}
+
+	return items
+}
+
+// cacheKey returns what tells the options apart in the key of a cached match.
+func (o findOptions) cacheKey() string {
+	if o.duration <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\x00%d\x00%d\x00%t", o.duration, o.tolerance, o.requireDuration)
+}
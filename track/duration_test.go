@@ -0,0 +1,120 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/joarleth/spotify/track/tracktest"
+)
+
+const (
+	extendedMixId = "1aaaaaaaaaaaaaaaaaaaaa"
+	radioEditId   = "2bbbbbbbbbbbbbbbbbbbbb"
+)
+
+func newDurationServer() *tracktest.Server {
+	artists := []tracktest.Artist{{Id: "3ccccccccccccccccccccc", Name: "Someone"}}
+
+	return tracktest.NewServer(
+		tracktest.Track{Id: extendedMixId, Name: "Song", Artists: artists, DurationMs: 412000},
+		tracktest.Track{Id: radioEditId, Name: "Song", Artists: artists, DurationMs: 215000},
+	)
+}
+
+func TestFindPreferDuration(t *testing.T) {
+	server := newDurationServer()
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	actual, err := s.Find("Song", "Someone", "", PreferDuration(216*time.Second, 0))
+
+	if err != nil || actual.Uri != "spotify:track:"+radioEditId {
+		t.Fatalf("Expected the radio edit. Actual: %v, %v", actual, err)
+	}
+
+	if actual.Match.DurationDelta != time.Second {
+		t.Errorf("Expected DurationDelta to be 1s. Actual: %v", actual.Match.DurationDelta)
+	}
+
+	actual, err = s.Find("Song", "Someone", "", PreferDuration(300*time.Second, time.Second))
+
+	if err != nil || actual.Uri != "spotify:track:"+extendedMixId {
+		t.Errorf("Expected the first track when none is within the window. Actual: %v, %v", actual, err)
+	}
+}
+
+func TestFindRequireDuration(t *testing.T) {
+	server := newDurationServer()
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	actual, err := s.Find("Song", "Someone", "", RequireDuration(410*time.Second, 5*time.Second))
+
+	if err != nil || actual.Uri != "spotify:track:"+extendedMixId {
+		t.Errorf("Expected the extended mix. Actual: %v, %v", actual, err)
+	}
+
+	_, err = s.Find("Song", "Someone", "", RequireDuration(300*time.Second, 5*time.Second))
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound. Actual: %v", err)
+	}
+}
+
+func TestFindClosestMatchDuration(t *testing.T) {
+	server := newDurationServer()
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	actual, confidence, err := s.FindClosestMatch("Song", "Someone", "", PreferDuration(214*time.Second, 0))
+
+	if err != nil || actual.Uri != "spotify:track:"+radioEditId {
+		t.Fatalf("Expected the radio edit. Actual: %v, %v", actual, err)
+	}
+
+	if confidence != 1 {
+		t.Errorf("Expected a track within the tolerance to have confidence 1. Actual: %v", confidence)
+	}
+
+	_, _, err = s.FindClosestMatch("Song", "Someone", "", RequireDuration(time.Minute, 0))
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound. Actual: %v", err)
+	}
+}
+
+func TestFindManyDurationOptions(t *testing.T) {
+	server := newDurationServer()
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL), WithCache(NewLRUCache(10)))
+
+	results := s.FindMany(context.Background(), []FindRequest{
+		{Title: "Song", Artist: "Someone", Options: []FindOption{PreferDuration(412*time.Second, 0)}},
+		{Title: "Song", Artist: "Someone", Options: []FindOption{PreferDuration(215*time.Second, 0)}},
+	}, FindManyOptions{})
+
+	if results[0].Track.Uri != "spotify:track:"+extendedMixId || results[1].Track.Uri != "spotify:track:"+radioEditId {
+		t.Errorf("Expected requests of different durations to find different tracks. Actual: %v", results)
+	}
+}
+
+func TestScoreDurationTolerance(t *testing.T) {
+	candidate := Track{Name: "Song", Duration: 200 * time.Second}
+	target := matchTarget{title: "Song", duration: 210 * time.Second, tolerance: 10 * time.Second}
+
+	if actual := (matcher{}).score(candidate, target); actual.Confidence != 1 || actual.DurationDelta != 10*time.Second {
+		t.Errorf("Expected a delta within the tolerance not to lower the confidence. Actual: %+v", actual)
+	}
+
+	target.tolerance = 0
+
+	if actual := (matcher{}).score(candidate, target); actual.Confidence >= 1 {
+		t.Errorf("Expected a delta beyond the tolerance to lower the confidence. Actual: %+v", actual)
+	}
+}
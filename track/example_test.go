@@ -2,12 +2,21 @@ package track
 
 import (
 	"fmt"
+
+	"github.com/joarleth/spotify/track/tracktest"
 )
 
-// Since this example runs code retrieving data from an external API
-// The output may well change in the future.
+// The example searches a tracktest.Server rather than Spotify, so that its
+// output stays the same.
 func ExampleSearcher() {
-	s := NewSearcher()
+	server := tracktest.NewServer(tracktest.Track{
+		Id:      "2NhEuDWWEeILAScaN2iPF4",
+		Name:    "Lazarus",
+		Artists: []tracktest.Artist{{Id: "2x9SpqnPi8rlE9pjHBwmSC", Name: "David Byrne"}},
+	})
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
 
 	track, err := s.Find("lazarus", "david byrne", "")
 
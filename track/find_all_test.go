@@ -0,0 +1,226 @@
+package track
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// newPagingServer returns a server serving the tracks in test_data/tracks.json
+// in pages, following the offset and limit parameters of the request.
+func newPagingServer(t *testing.T, requests *int) *httptest.Server {
+	tc, err := extractTrackCollectionFromJSON(getTextFileData(t, "test_data/tracks.json"))
+
+	if err != nil {
+		t.Fatalf("Failed to extract tracks. Error: %v", err.Error())
+	}
+
+	var ts *httptest.Server
+
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		items := tc.Tracks.Items
+
+		end := min(offset+limit, len(items))
+		page := trackCollection{Tracks: trackItem{Items: items[min(offset, end):end], Offset: offset, Limit: limit, Total: len(items)}}
+
+		if end < len(items) {
+			page.Tracks.Next = fmt.Sprintf("%s/?type=track&q=%s&offset=%d&limit=%d", ts.URL, url.QueryEscape(r.URL.Query().Get("q")), end, limit)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+
+	return ts
+}
+
+func TestFindAllFollowsNextPages(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	result, err := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Offset: 2, Limit: 15})
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	tracks := result.Tracks
+
+	if result.Total != 20 {
+		t.Errorf("Unexpected total. Expected: 20, got: %d", result.Total)
+	}
+
+	if result.NextOffset != 17 {
+		t.Errorf("Unexpected next offset. Expected: 17, got: %d", result.NextOffset)
+	}
+
+	if len(tracks) != 15 {
+		t.Fatalf("Unexpected number of tracks. Expected: 15, got: %d", len(tracks))
+	}
+
+	expectedFirstUri := "spotify:track:76v67pMvrgqYJ45s0ynsl1"
+
+	if tracks[0].Uri != expectedFirstUri {
+		t.Errorf("Unexpected first track.\nExpected: %s\nActual:   %s", expectedFirstUri, tracks[0].Uri)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
+
+func TestFindAllLimitAbovePageSize(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	result, _ := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Offset: 15, Limit: 60})
+
+	if len(result.Tracks) != 5 {
+		t.Errorf("Unexpected number of tracks. Expected: 5, got: %d", len(result.Tracks))
+	}
+
+	if result.NextOffset != 0 {
+		t.Errorf("Expected no next offset at the end of the tracks. Got: %d", result.NextOffset)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
+
+func TestFindAllPagesThroughMarketFilteredTracks(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMarket("US"))
+
+	result, _ := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Limit: 5})
+
+	if len(result.Tracks) != 3 {
+		t.Errorf("Unexpected number of tracks. Expected: 3, got: %d", len(result.Tracks))
+	}
+
+	if result.Total != 20 {
+		t.Errorf("Unexpected total. Expected: 20, got: %d", result.Total)
+	}
+
+	if requests != 4 {
+		t.Errorf("Expected 4 requests. Got: %d", requests)
+	}
+}
+
+func TestFindAllNegativeLimitReturnsArgumentError(t *testing.T) {
+	s := NewSearcher()
+
+	_, err := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Limit: -1})
+
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		t.Fatal("Expected error to be of type TrackError.")
+	}
+
+	if terr.ErrorType != ArgumentError {
+		t.Error("Expected ErrorType to be ArgumentError.")
+	}
+}
+
+func TestFindAllNextOffsetSkipsMarketFilteredTracks(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMarket("US"))
+
+	all, _ := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Limit: 20})
+
+	var paged []Track
+	opts := FindAllOptions{Limit: 2}
+
+	for {
+		result, err := s.FindAll("Human Behaviour", "Björk", "", opts)
+
+		if err != nil {
+			t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+		}
+
+		paged = append(paged, result.Tracks...)
+
+		if result.NextOffset == 0 {
+			break
+		}
+
+		opts = FindAllOptions{Offset: result.NextOffset, Limit: 2, QueryLevel: result.QueryLevel}
+	}
+
+	if len(paged) != len(all.Tracks) {
+		t.Fatalf("Expected paging to return %d tracks. Got: %d", len(all.Tracks), len(paged))
+	}
+
+	for i := range paged {
+		if paged[i].Uri != all.Tracks[i].Uri {
+			t.Errorf("Unexpected track %d.\nExpected: %s\nActual:   %s", i, all.Tracks[i].Uri, paged[i].Uri)
+		}
+	}
+}
+
+func TestFindAllFallsBackOnlyOnFirstPage(t *testing.T) {
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+
+		if q == `track:"Human Behaviour" artist:"Björk"` {
+			w.Write([]byte(oneTrackJSON))
+			return
+		}
+
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	first, _ := s.FindAll("Human Behaviour", "Björk", "Debut", FindAllOptions{})
+
+	if len(first.Tracks) != 1 || first.QueryLevel != TitleArtistQuery {
+		t.Fatalf("Expected a track from the title and artist query. Got: %v", first)
+	}
+
+	queries = nil
+
+	s.FindAll("Human Behaviour", "Björk", "Debut", FindAllOptions{Offset: 1, QueryLevel: first.QueryLevel})
+
+	if len(queries) != 1 || queries[0] != `track:"Human Behaviour" artist:"Björk"` {
+		t.Errorf("Expected a single query for the following page. Got: %v", queries)
+	}
+
+	queries = nil
+
+	s.FindAll("Human Behaviour", "Björk", "Debut", FindAllOptions{Offset: 1})
+
+	if len(queries) != 1 || queries[0] != `track:"Human Behaviour" artist:"Björk" album:"Debut"` {
+		t.Errorf("Expected the first query when no QueryLevel is given. Got: %v", queries)
+	}
+
+	if _, err := s.FindAll("Human Behaviour", "Björk", "", FindAllOptions{Offset: 1, QueryLevel: TitleAlbumQuery}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for a query not searched. Got: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package track
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFindByISRC(t *testing.T) {
+	tc, _ := extractTrackCollectionFromJSON(getTextFileData(t, "test_data/tracks.json"))
+	tc.Tracks.Next = ""
+
+	var query string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tc)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	tracks, err := s.FindByISRC("gb-btf-93-00001")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	expectedQuery := "isrc:GBBTF9300001"
+
+	if query != expectedQuery {
+		t.Errorf("Unexpected search query.\nExpected: %s\nActual:   %s", expectedQuery, query)
+	}
+
+	if len(tracks) != 20 {
+		t.Errorf("Unexpected number of tracks. Expected: 20, got: %d", len(tracks))
+	}
+
+	if len(tracks) > 0 && tracks[0].QueryLevel != IsrcQuery {
+		t.Errorf("Expected QueryLevel to be IsrcQuery. Got: %v", tracks[0].QueryLevel)
+	}
+}
+
+func TestFindByISRCInMarket(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMarket("SE"))
+
+	tracks, _ := s.FindByISRC("GBBTF9300001")
+
+	if len(tracks) != 13 {
+		t.Errorf("Unexpected number of tracks. Expected: 13, got: %d", len(tracks))
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
+
+func TestFindByISRCInvalidISRCReturnsArgumentError(t *testing.T) {
+	s := NewSearcher()
+
+	_, err := s.FindByISRC("GBBTF93")
+
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		t.Fatal("Expected error to be of type TrackError.")
+	}
+
+	if terr.ErrorType != ArgumentError {
+		t.Error("Expected ErrorType to be ArgumentError.")
+	}
+}
+
+func TestConstructIsrcSearchQuery(t *testing.T) {
+	expected := url.QueryEscape("isrc:USRC17607839")
+	actual, err := constructIsrcSearchQuery(" US-RC1-76-07839 ")
+
+	if err != nil {
+		t.Errorf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if expected != actual {
+		t.Errorf("Incorrect spotify search query.\nExpected: %s\nActual:   %s", expected, actual)
+	}
+}
+
+func TestIsValidIsrc(t *testing.T) {
+	cases := map[string]bool{
+		"GBBTF9300001":  true,
+		"USRC17607839":  true,
+		"GB1239300001":  true,
+		"1BBTF9300001":  false,
+		"GBBTF93000A1":  false,
+		"GBBTF930001":   false,
+		"GBBTF93000011": false,
+		"":              false,
+	}
+
+	for isrc, expected := range cases {
+		if actual := isValidIsrc(isrc); expected != actual {
+			t.Errorf("Unexpected validity of %q. Expected: %v, got: %v", isrc, expected, actual)
+		}
+	}
+}
@@ -1,65 +1,128 @@
 package track
 
-/*
 import (
 	"net/http"
 	"net/http/httptest"
-	"reflect"
 	"testing"
 )
 
 func TestFindClosestMatch(t *testing.T) {
-	xml_data := getTextFileData(t, "tracks2.xml")
+	data := getTextFileData(t, "test_data/tracks.json")
+
+	var limit string
+
+	mockserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit = r.URL.Query().Get("limit")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer mockserver.Close()
+
+	s := newMockSearcher(mockserver.URL)
+
+	expectedUri := "spotify:track:0z1exf1SZhszjwPWPmXFub"
+	actual, score, err := s.FindClosestMatch("Human Behaviour", "Björk", "Debut")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if actual.Uri != expectedUri {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", expectedUri, actual.Uri)
+	}
+
+	if score != 1 {
+		t.Errorf("Expected score of exact match to be 1. Got: %v", score)
+	}
+
+	if limit != "50" {
+		t.Errorf("Expected limit to be 50. Got: %s", limit)
+	}
+}
+
+func TestFindClosestMatchPrefersSimilarAlbum(t *testing.T) {
+	data := getTextFileData(t, "test_data/tracks.json")
 
 	mockserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/xml")
-		w.Write(xml_data)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
 	}))
+	defer mockserver.Close()
+
+	s := newMockSearcher(mockserver.URL)
 
-	s := new_mock_searcher("SE", mockserver.URL)
+	expectedUri := "spotify:track:3Qc4ANe5NDaM2iCXX9WZpq"
+	actual, score, _ := s.FindClosestMatch("Human Behaviour", "Björk", "Homogenic Live")
 
-	expected := Track{
-		Name:        "Uncover",
-		Artists:     []string{"Zara Larsson"},
-		Album:       "Introducing",
-		Href:        "spotify:track:131l5GkXPIk81bxihGypPt",
-		Territories: "SE",
+	if actual.Uri != expectedUri {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", expectedUri, actual.Uri)
 	}
-	actual, _ := s.FindClosestMatch("Uncover", "Zara Larsson", "")
 
-	if !reflect.DeepEqual(expected, actual) {
-		t.Errorf("Resulting track not matching expected.\nExpected: %#v\nActual: %#v", expected, actual)
+	if score != 1 {
+		t.Errorf("Expected score of exact match to be 1. Got: %v", score)
 	}
 }
 
 func TestFindClosestMatchDifferentTerritory(t *testing.T) {
-	xml_data := getTextFileData(t, "tracks_bjork.xml")
+	data := getTextFileData(t, "test_data/tracks.json")
 
+	var market string
+
+	mockserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		market = r.URL.Query().Get("market")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer mockserver.Close()
+
+	s := newMockSearcher(mockserver.URL, WithMarket("US"))
+
+	expectedUri := "spotify:track:5OnyZ56HLhrWOXdzeETqLk"
+	actual, _, _ := s.FindClosestMatch("Human Behaviour", "Björk", "Debut")
+
+	if actual.Uri != expectedUri {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", expectedUri, actual.Uri)
+	}
+
+	if market != "US" {
+		t.Errorf("Expected market to be US. Got: %s", market)
+	}
+}
+
+func TestFindClosestMatchNoTracks(t *testing.T) {
 	mockserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/xml")
-		w.Write(xml_data)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(noTracksJSON))
 	}))
+	defer mockserver.Close()
+
+	s := newMockSearcher(mockserver.URL)
+
+	actual, score, err := s.FindClosestMatch("Human Behaviour", "Björk", "")
 
-	s := new_mock_searcher("US", mockserver.URL)
+	terr, isTrackError := err.(TrackError)
 
-	expected := Track{
-		Name:        "Human Behaviour",
-		Artists:     []string{"Björk"},
-		Album:       "Debut",
-		Href:        "spotify:track:5OnyZ56HLhrWOXdzeETqLk",
-		Territories: "CA US",
+	if !isTrackError || terr.ErrorType != NotFoundError {
+		t.Fatalf("Expected a TrackError with ErrorType NotFoundError. Got: %v", err)
 	}
-	actual, _ := s.FindClosestMatch("Uncover", "Zara Larsson", "")
 
-	if !reflect.DeepEqual(expected, actual) {
-		t.Errorf("Resulting track not matching expected.\nExpected: %#v\nActual: %#v", expected, actual)
+	expectedMessage := errorPrefix + `No track found searching for track:"Human Behaviour" artist:"Björk".`
+
+	if expectedMessage != err.Error() {
+		t.Errorf("Unexpected error message.\nExpected: %v\nActual: %v", expectedMessage, err.Error())
+	}
+
+	if actual.Uri != "" || score != 0 {
+		t.Errorf("Expected empty track and zero score. Got: %v, %v", actual, score)
 	}
 }
 
 func TestFindClosestMatchSearchQueryArgumentTrackError(t *testing.T) {
-	s := NewSearcher("SE")
+	s := NewSearcher()
 
-	_, err := s.FindClosestMatch("john", "", "")
+	_, _, err := s.FindClosestMatch("john", "", "")
 
 	if err == nil {
 		t.Fatal("Expected error.")
@@ -82,10 +145,6 @@ func TestFindClosestMatchSearchQueryArgumentTrackError(t *testing.T) {
 	}
 }
 
-func new_mock_searcher(territory, search_url string) *Searcher {
-	return &Searcher{
-		territory:             territory,
-		track_search_base_url: search_url,
-	}
+func newMockSearcher(baseUrl string, opts ...Option) *Searcher {
+	return NewSearcher(append([]Option{WithBaseURL(baseUrl)}, opts...)...)
 }
-*/
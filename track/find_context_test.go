@@ -0,0 +1,99 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindContextCancelledReturnsCanceledError(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.FindContext(ctx, "Human Behaviour", "Björk", "Debut")
+
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		t.Fatalf("Expected error to be of type TrackError. Got: %v", err)
+	}
+
+	if terr.ErrorType != CanceledError {
+		t.Error("Expected ErrorType to be CanceledError.")
+	}
+
+	if terr.OriginalError != context.Canceled {
+		t.Errorf("Expected OriginalError to be context.Canceled. Got: %v", terr.OriginalError)
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests. Got: %d", requests)
+	}
+}
+
+func TestFindContextDeadlineStopsHungRequest(t *testing.T) {
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	s := newMockSearcher(ts.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := s.FindClosestMatchContext(ctx, "Human Behaviour", "Björk", "Debut")
+
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		t.Fatalf("Expected error to be of type TrackError. Got: %v", err)
+	}
+
+	if terr.ErrorType != CanceledError {
+		t.Error("Expected ErrorType to be CanceledError.")
+	}
+
+	if terr.OriginalError != context.DeadlineExceeded {
+		t.Errorf("Expected OriginalError to be context.DeadlineExceeded. Got: %v", terr.OriginalError)
+	}
+}
+
+func TestFindAllContextStopsPagingWhenCancelled(t *testing.T) {
+	requests := 0
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		w.Write([]byte(`{"tracks": {"items": [{"uri": "spotify:track:1"}], "next": "http://` + r.Host + `/next", "total": 2}}`))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	_, err := s.FindAllContext(ctx, "Human Behaviour", "Björk", "", FindAllOptions{Limit: 2})
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != CanceledError {
+		t.Errorf("Expected a TrackError with ErrorType CanceledError. Got: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
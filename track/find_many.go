@@ -0,0 +1,121 @@
+package track
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultFindManyWorkers is the number of lookups FindMany runs at the same
+// time when no number is given in FindManyOptions.
+const defaultFindManyWorkers = 4
+
+// FindRequest is one row of tracks to look up with FindMany, holding the same
+// title, artist, album and options as taken by Find.
+type FindRequest struct {
+	Title   string
+	Artist  string
+	Album   string
+	Options []FindOption
+}
+
+// FindResult is the outcome of looking up a FindRequest. Track and Err are
+// what Find returned for it.
+type FindResult struct {
+	Track Track
+	Err   error
+}
+
+// FindManyOptions configures FindMany. Workers is the largest number of
+// lookups run at the same time, defaulting to defaultFindManyWorkers. When
+// Progress is set, it is called with the number of requests done so far and
+// the total number of requests every time a lookup finishes. It is never
+// called from two goroutines at once.
+type FindManyOptions struct {
+	Workers  int
+	Progress func(done, total int)
+}
+
+// FindMany looks up every request with FindContext, running up to
+// opts.Workers lookups at the same time, and returns their results in the order
+// of the requests. Requests that only differ in case, whitespace or what the
+// Normalizer of the Searcher removes are looked up once and given the same result.
+//
+// The lookups share the Searcher, so a RateLimiter set with WithRateLimiter
+// keeps the whole batch under its rate. If ctx is cancelled, the requests not
+// looked up yet get a TrackError with ErrorType CanceledError.
+func (s Searcher) FindMany(ctx context.Context, requests []FindRequest, opts FindManyOptions) []FindResult {
+	results := make([]FindResult, len(requests))
+
+	// Rows of identical requests, in the order the requests were first seen.
+	var keys []string
+	rows := make(map[string][]int)
+
+	for i, r := range requests {
+		key := s.matchKey(r.Title, r.Artist, r.Album, newFindOptions(r.Options))
+
+		if _, seen := rows[key]; !seen {
+			keys = append(keys, key)
+		}
+
+		rows[key] = append(rows[key], i)
+	}
+
+	workers := opts.Workers
+
+	if workers < 1 {
+		workers = defaultFindManyWorkers
+	}
+
+	workers = min(workers, len(keys))
+
+	type outcome struct {
+		key    string
+		result FindResult
+	}
+
+	jobs := make(chan string)
+	outcomes := make(chan outcome)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for key := range jobs {
+				r := requests[rows[key][0]]
+				track, err := s.FindContext(ctx, r.Title, r.Artist, r.Album, r.Options...)
+
+				outcomes <- outcome{key, FindResult{track, err}}
+			}
+		}()
+	}
+
+	go func() {
+		for _, key := range keys {
+			jobs <- key
+		}
+
+		close(jobs)
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	done := 0
+
+	for o := range outcomes {
+		for _, i := range rows[o.key] {
+			results[i] = o.result
+		}
+
+		done += len(rows[o.key])
+
+		if opts.Progress != nil {
+			opts.Progress(done, len(requests))
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,195 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFindManyReturnsResultsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	queries := make(map[string]int)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+
+		mu.Lock()
+		queries[q]++
+		mu.Unlock()
+
+		if q == `track:"Missing" artist:"Nobody"` {
+			w.Write([]byte(noTracksJSON))
+			return
+		}
+
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	requests := []FindRequest{
+		{Title: "Human Behaviour", Artist: "Björk"},
+		{Title: "Missing", Artist: "Nobody"},
+		{Title: "", Artist: "Björk"},
+		{Title: " human behaviour", Artist: "BJÖRK"},
+	}
+
+	var progress []int
+
+	results := s.FindMany(context.Background(), requests, FindManyOptions{
+		Workers: 2,
+		Progress: func(done, total int) {
+			if total != len(requests) {
+				t.Errorf("Expected total to be %d. Got: %d", len(requests), total)
+			}
+
+			progress = append(progress, done)
+		},
+	})
+
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results. Got: %d", len(requests), len(results))
+	}
+
+	for _, i := range []int{0, 3} {
+		if results[i].Err != nil || results[i].Track.Uri != "spotify:track:4ry6oqlwdsooYtniYJFkt5" {
+			t.Errorf("Unexpected result %d: %v", i, results[i])
+		}
+	}
+
+	if !errors.Is(results[1].Err, ErrNotFound) {
+		t.Errorf("Expected result 1 to be ErrNotFound. Got: %v", results[1].Err)
+	}
+
+	if !errors.Is(results[2].Err, ErrInvalidArgument) {
+		t.Errorf("Expected result 2 to be ErrInvalidArgument. Got: %v", results[2].Err)
+	}
+
+	if n := queries[`track:"Human Behaviour" artist:"Björk"`]; n != 1 {
+		t.Errorf("Expected identical requests to be searched for once. Got: %d", n)
+	}
+
+	if len(progress) != 3 || progress[len(progress)-1] != len(requests) {
+		t.Errorf("Unexpected progress: %v", progress)
+	}
+}
+
+func TestFindManyLimitsWorkers(t *testing.T) {
+	var inFlight, most int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			m := atomic.LoadInt32(&most)
+
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	var requests []FindRequest
+
+	for _, title := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		requests = append(requests, FindRequest{Title: title, Artist: "Björk"})
+	}
+
+	results := s.FindMany(context.Background(), requests, FindManyOptions{Workers: 3})
+
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("Expected error %d to be nil. Got: %s", i, result.Err.Error())
+		}
+	}
+
+	if most > 3 {
+		t.Errorf("Expected at most 3 requests at the same time. Got: %d", most)
+	}
+}
+
+func TestFindManyCanceled(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := s.FindMany(ctx, []FindRequest{{Title: "a", Artist: "b"}, {Title: "c", Artist: "d"}}, FindManyOptions{})
+
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected error %d to be context.Canceled. Got: %v", i, result.Err)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests. Got: %d", requests)
+	}
+}
+
+func TestFindManyCanceledSkipsMatchCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMatchCache(NewLRUCache(10)))
+
+	if _, err := s.Find("Human Behaviour", "Björk", ""); err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := s.FindMany(ctx, []FindRequest{{Title: "Human Behaviour", Artist: "Björk"}}, FindManyOptions{})
+
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("Expected a cached request to be context.Canceled too. Got: %v", results[0])
+	}
+}
+
+func TestFindManyDeduplicatesNormalizedRequests(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithNormalizer(DefaultNormalizer))
+
+	results := s.FindMany(context.Background(), []FindRequest{
+		{Title: "Human Behaviour (2011 Remaster)", Artist: "Björk"},
+		{Title: "Human Behaviour", Artist: "Bjork"},
+	}, FindManyOptions{})
+
+	if results[0].Err != nil || results[1].Track.Uri != results[0].Track.Uri {
+		t.Errorf("Expected both requests to get the same track. Got: %v", results)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("Expected a single search. Got: %d", n)
+	}
+}
@@ -0,0 +1,199 @@
+package track
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/joarleth/spotify/track/tracktest"
+)
+
+const noTracksJSON = `{"tracks": {"href": "", "items": []}}`
+
+const oneTrackJSON = `{"tracks": {"href": "", "items": [{"uri": "spotify:track:4ry6oqlwdsooYtniYJFkt5", "name": "Human Behaviour"}], "total": 1}}`
+
+func TestFindFallsBackToTitleAndArtist(t *testing.T) {
+	data := getTextFileData(t, "test_data/tracks.json")
+
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.Contains(q, "album:") {
+			w.Write([]byte(noTracksJSON))
+			return
+		}
+
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	track, err := s.Find("Human Behaviour", "Björk", "Debut (Remastered)")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	expectedUri := "spotify:track:4ry6oqlwdsooYtniYJFkt5"
+
+	if track.Uri != expectedUri {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", expectedUri, track.Uri)
+	}
+
+	if track.QueryLevel != TitleArtistQuery {
+		t.Errorf("Expected QueryLevel to be TitleArtistQuery. Got: %v", track.QueryLevel)
+	}
+
+	if len(queries) != 2 {
+		t.Errorf("Expected 2 search requests. Got: %d", len(queries))
+	}
+}
+
+func TestFindTriesEveryQueryBeforeGivingUp(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	track, err := s.Find("Human Behaviour", "Björk", "Debut")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected error to be ErrNotFound. Got: %v", err)
+	}
+
+	expectedQueries := []string{
+		`track:"Human Behaviour" artist:"Björk" album:"Debut"`,
+		`track:"Human Behaviour" artist:"Björk"`,
+		`track:"Human Behaviour" album:"Debut"`,
+	}
+
+	if terr := err.(TrackError); !reflect.DeepEqual(expectedQueries, terr.Queries) {
+		t.Errorf("Unexpected queries tried.\nExpected: %v\nActual:   %v", expectedQueries, terr.Queries)
+	}
+
+	if track.QueryLevel != NoQueryLevel {
+		t.Errorf("Expected QueryLevel to be NoQueryLevel. Got: %v", track.QueryLevel)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 search requests. Got: %d", requests)
+	}
+}
+
+func TestSearchQueryLevels(t *testing.T) {
+	expected := []QueryLevel{TitleArtistAlbumQuery, TitleArtistQuery, TitleAlbumQuery}
+	_, actual, _ := constructSearchQuery("asdf", "qwer", "ty")
+
+	if len(expected) != len(actual) {
+		t.Fatalf("Unexpected query levels.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("Unexpected query levels.\nExpected: %v\nActual:   %v", expected, actual)
+		}
+	}
+
+	for _, artist := range []string{" ", `"`, `\ "`} {
+		if queries, levels, _ := constructSearchQuery("asdf", artist, "ty"); len(queries) != 1 || len(levels) != 1 || levels[0] != TitleAlbumQuery {
+			t.Errorf("Expected only TitleAlbumQuery for artist %q. Got: %v", artist, levels)
+		}
+	}
+}
+
+func TestQuoteOnlyArtistIsLeftOut(t *testing.T) {
+	server := tracktest.NewServer(
+		tracktest.Track{Id: "4ry6oqlwdsooYtniYJFkt5", Name: "Song", Artists: []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Someone"}}, Album: tracktest.Album{Id: "3icOCBqUqz5JJl9ajZzBwf", Name: "Album"}},
+	)
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL), WithNormalizer(DefaultNormalizer))
+
+	for _, artist := range []string{"“”", "＂"} {
+		track, err := s.Find("Song", artist, "Album")
+
+		if err != nil || track.QueryLevel != TitleAlbumQuery {
+			t.Errorf("Expected a track found by title and album for artist %q. Got: %v, %v", artist, track, err)
+		}
+
+		all, err := s.FindAll("Song", artist, "Album", FindAllOptions{})
+
+		if err != nil || len(all.Tracks) != 1 || all.QueryLevel != TitleAlbumQuery {
+			t.Errorf("Expected FindAll to find the track by title and album for artist %q. Got: %v, %v", artist, all, err)
+		}
+
+		if _, err := s.FindAll("Song", artist, "Album", FindAllOptions{Offset: 1, QueryLevel: TitleAlbumQuery}); err != nil {
+			t.Errorf("Expected error to be nil for artist %q. Got: %v", artist, err)
+		}
+
+		if _, err := s.FindAll("Song", artist, "Album", FindAllOptions{Offset: 1, QueryLevel: TitleArtistQuery}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Expected ErrInvalidArgument for a query not searched with artist %q. Got: %v", artist, err)
+		}
+	}
+}
+
+func TestFindSkipsTracksNotPlayableInMarket(t *testing.T) {
+	data := getTextFileData(t, "test_data/tracks.json")
+
+	var limit string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit = r.URL.Query().Get("limit")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMarket("US"))
+
+	track, _ := s.Find("Human Behaviour", "Björk", "")
+
+	expectedUri := "spotify:track:6scvoA7nOKC5EZCi31R6WW"
+
+	if track.Uri != expectedUri {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", expectedUri, track.Uri)
+	}
+
+	if limit != "20" {
+		t.Errorf("Expected limit to be 20. Got: %s", limit)
+	}
+}
+
+func TestItemIsPlayableIn(t *testing.T) {
+	playable := true
+	notPlayable := false
+
+	cases := []struct {
+		candidate item
+		expected  bool
+	}{
+		{item{AvailableMarkets: []string{"SE", "NO"}}, true},
+		{item{AvailableMarkets: []string{"NO"}}, false},
+		{item{Album: album{AvailableMarkets: []string{"SE"}}}, true},
+		{item{IsPlayable: &notPlayable, AvailableMarkets: []string{"SE"}}, false},
+		{item{IsPlayable: &playable}, true},
+		{item{}, true},
+	}
+
+	for _, c := range cases {
+		if actual := c.candidate.isPlayableIn("SE"); c.expected != actual {
+			t.Errorf("Unexpected result of isPlayableIn for %#v. Expected: %v, got: %v", c.candidate, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package track
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/joarleth/spotify/spotifyid"
+)
+
+// maxTracksPerRequest is the largest number of track ids the API takes in one
+// request for several tracks.
+const maxTracksPerRequest = 50
+
+// tracksPath is the path of the API endpoint returning tracks by id, relative
+// to the base url.
+const tracksPath = "/tracks?ids="
+
+// GetTrack returns the track from Spotify with the id id. It is useful for
+// refreshing the metadata of a track found earlier, whose URI or link can be
+// turned into a TrackID with spotifyid.ParseTrackID.
+// If Spotify has no track with the id, a TrackError with ErrorType
+// NotFoundError is returned.
+func (s Searcher) GetTrack(id spotifyid.TrackID) (Track, error) {
+	return s.GetTrackContext(context.Background(), id)
+}
+
+// GetTrackContext is like GetTrack, but the request to Spotify is made with ctx.
+func (s Searcher) GetTrackContext(ctx context.Context, id spotifyid.TrackID) (Track, error) {
+	tracks, err := s.GetTracksContext(ctx, []spotifyid.TrackID{id})
+
+	if err != nil {
+		return Track{}, err
+	}
+
+	if tracks[0].Uri == "" {
+		return Track{}, TrackError{Msg: fmt.Sprintf("No track found with id %q.", tracks[0].Id), ErrorType: NotFoundError}
+	}
+
+	return tracks[0], nil
+}
+
+// GetTracks returns the tracks from Spotify with the ids ids, in the same order.
+// The ids are fetched in requests of up to maxTracksPerRequest ids each.
+// Spotify returns nothing for ids it has no track for, and the tracks returned
+// for them only have their Id set, with an empty Uri.
+// The ids must be bare ids, as returned by spotifyid.ParseTrackID. If any of
+// them is not, a TrackError with ErrorType ArgumentError is returned and nothing
+// is fetched.
+func (s Searcher) GetTracks(ids []spotifyid.TrackID) ([]Track, error) {
+	return s.GetTracksContext(context.Background(), ids)
+}
+
+// GetTracksContext is like GetTracks, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before all tracks are fetched, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) GetTracksContext(ctx context.Context, ids []spotifyid.TrackID) ([]Track, error) {
+	trackIds := make([]string, len(ids))
+
+	for i, id := range ids {
+		if !id.Valid() {
+			return nil, TrackError{Msg: fmt.Sprintf("%q passed to GetTracks is not a bare track id. URIs and links are parsed with spotifyid.ParseTrackID.", id), ErrorType: ArgumentError}
+		}
+
+		trackIds[i] = id.String()
+	}
+
+	tracks := make([]Track, 0, len(trackIds))
+
+	for start := 0; start < len(trackIds); start += maxTracksPerRequest {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		chunk := trackIds[start:min(start+maxTracksPerRequest, len(trackIds))]
+
+		s.logger().DebugContext(ctx, "fetching tracks", "method", "GetTracks", "ids", len(chunk))
+
+		data, fetchError := s.fetchData(ctx, s.tracksUrl(chunk))
+
+		if fetchError != nil {
+			return nil, fetchError
+		}
+
+		items, extractError := extractTrackListFromJSON(data)
+
+		if extractError != nil {
+			return nil, extractError
+		}
+
+		if len(items) != len(chunk) {
+			return nil, TrackError{Msg: fmt.Sprintf("Expected %d tracks from Spotify, got %d.", len(chunk), len(items)), ErrorType: ExternalServiceError}
+		}
+
+		for i, trackItem := range items {
+			if trackItem == nil {
+				tracks = append(tracks, Track{Id: chunk[i]})
+				continue
+			}
+
+			tracks = append(tracks, trackFromItem(*trackItem))
+		}
+	}
+
+	return tracks, nil
+}
+
+// tracksUrl returns the url fetching the tracks with the ids ids, relinked
+// to the market of the Searcher.
+func (s Searcher) tracksUrl(ids []string) string {
+	tracksUrl := s.baseUrl + tracksPath + url.QueryEscape(strings.Join(ids, ","))
+
+	if s.market != "" {
+		tracksUrl += "&market=" + url.QueryEscape(s.market)
+	}
+
+	return tracksUrl
+}
+
+// trackList is used for unmarshalling the tracks returned by id, where the
+// ids Spotify has no track for are null.
+type trackList struct {
+	Tracks []*item
+}
+
+func extractTrackListFromJSON(jsonData []byte) ([]*item, error) {
+	var tl trackList
+	err := json.Unmarshal(jsonData, &tl)
+
+	if err != nil {
+		return nil, TrackError{Msg: "Unable to unmarshal jsonData in extractTrackListFromJSON.", OriginalError: err, ErrorType: ExternalServiceError}
+	}
+
+	return tl.Tracks, nil
+}
@@ -0,0 +1,135 @@
+package track
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joarleth/spotify/spotifyid"
+)
+
+// missingId is an id newTracksServer has no track for.
+const missingId = "missingmissingmissing0"
+
+// newTracksServer returns a server answering requests for tracks by id with a
+// track for every id except missingId, and appending the ids of every request
+// to requests.
+func newTracksServer(t *testing.T, requests *[][]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tracks" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		*requests = append(*requests, ids)
+
+		var tracks []*item
+
+		for _, id := range ids {
+			if id == missingId {
+				tracks = append(tracks, nil)
+				continue
+			}
+
+			tracks = append(tracks, &item{Id: id, Uri: spotifyid.TrackID(id).URI(), Name: "Track " + id})
+		}
+
+		json.NewEncoder(w).Encode(trackList{Tracks: tracks})
+	}))
+}
+
+func TestGetTracksInChunks(t *testing.T) {
+	var requests [][]string
+
+	ts := newTracksServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	var ids []spotifyid.TrackID
+
+	for i := 0; i < 120; i++ {
+		ids = append(ids, spotifyid.TrackID(fmt.Sprintf("%022d", i)))
+	}
+
+	ids[7] = missingId
+	ids[60], _ = spotifyid.ParseTrackID("spotify:track:0000000000000000000060")
+
+	tracks, err := s.GetTracks(ids)
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if len(requests) != 3 || len(requests[0]) != 50 || len(requests[1]) != 50 || len(requests[2]) != 20 {
+		t.Errorf("Expected requests of 50, 50 and 20 ids. Got %d requests", len(requests))
+	}
+
+	if len(tracks) != len(ids) {
+		t.Fatalf("Expected %d tracks. Got: %d", len(ids), len(tracks))
+	}
+
+	if tracks[7].Uri != "" || tracks[7].Id != missingId {
+		t.Errorf("Expected the missing track to only have its id. Got: %v", tracks[7])
+	}
+
+	if tracks[60].Uri != "spotify:track:0000000000000000000060" {
+		t.Errorf("Unexpected track uri.\nExpected: %s\nActual:   %s", "spotify:track:0000000000000000000060", tracks[60].Uri)
+	}
+
+	if tracks[119].Name != "Track 0000000000000000000119" {
+		t.Errorf("Expected the tracks in the order of the ids. Got: %s", tracks[119].Name)
+	}
+}
+
+func TestGetTrack(t *testing.T) {
+	var requests [][]string
+
+	ts := newTracksServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	id, err := spotifyid.ParseTrackID("spotify:track:4ry6oqlwdsooYtniYJFkt5")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	track, err := s.GetTrack(id)
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if track.Id != "4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Unexpected track id: %s", track.Id)
+	}
+
+	if _, err := s.GetTrack(missingId); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error to be ErrNotFound. Got: %v", err)
+	}
+}
+
+func TestGetTracksInvalidId(t *testing.T) {
+	var requests [][]string
+
+	ts := newTracksServer(t, &requests)
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	for _, id := range []spotifyid.TrackID{"", "abc", "spotify:track:", "4ry6oqlwdsooYtniYJFkt5,", "spotify:track:4ry6oqlwdsooYtniYJFkt5"} {
+		if _, err := s.GetTracks([]spotifyid.TrackID{"4ry6oqlwdsooYtniYJFkt5", id}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Expected error for %q to be ErrInvalidArgument. Got: %v", id, err)
+		}
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests. Got: %d", len(requests))
+	}
+}
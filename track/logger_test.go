@@ -0,0 +1,93 @@
+package track
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogHandlerLogsSearch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("q"), "album:") {
+			w.Write([]byte(noTracksJSON))
+			return
+		}
+
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	s := newMockSearcher(ts.URL, WithLogHandler(handler))
+
+	s.Find("Human Behaviour", "Björk", "Debut")
+
+	var records []map[string]interface{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to unmarshal log record %q. Error: %v", line, err.Error())
+		}
+
+		records = append(records, record)
+	}
+
+	expectedMessages := []string{"searching", "request", "searching", "request", "track found"}
+
+	if len(records) != len(expectedMessages) {
+		t.Fatalf("Unexpected number of log records. Expected: %d, got: %d\n%s", len(expectedMessages), len(records), buf.String())
+	}
+
+	for i, expected := range expectedMessages {
+		if records[i]["msg"] != expected {
+			t.Errorf("Unexpected message of log record %d. Expected: %s, got: %v", i, expected, records[i]["msg"])
+		}
+	}
+
+	if records[1]["status"] != float64(http.StatusOK) || records[1]["latency"] == nil {
+		t.Errorf("Expected request to be logged with status and latency. Got: %v", records[1])
+	}
+
+	if records[2]["query_level"] != "title+artist" || records[2]["step"] != float64(2) {
+		t.Errorf("Expected second search step to be logged with its query level. Got: %v", records[2])
+	}
+
+	if records[4]["level"] != "INFO" || records[4]["uri"] != "spotify:track:4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Expected found track to be logged at info level. Got: %v", records[4])
+	}
+}
+
+func TestLogHandlerLogsFailedRequestsAsWarnings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
+
+	s := newMockSearcher(ts.URL, WithLogHandler(handler))
+
+	s.Find("Human Behaviour", "Björk", "")
+
+	if !strings.Contains(buf.String(), "level=WARN msg=request") || !strings.Contains(buf.String(), "status=502") {
+		t.Errorf("Expected failed request to be logged as a warning. Got: %s", buf.String())
+	}
+}
+
+func TestSearcherLogsNothingByDefault(t *testing.T) {
+	s := NewSearcher()
+
+	if s.logger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("Expected the default logger to be disabled.")
+	}
+}
@@ -1,21 +1,91 @@
 package track
 
-/*
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewSearcher(t *testing.T) {
 	expected := &Searcher{
-		territory:             "EE",
-		track_search_base_url: trackSearchBaseUrl,
+		baseUrl:            defaultBaseUrl,
+		trackSearchBaseUrl: "https://api.spotify.com/v1/search/?type=track&q=",
+		client:             http.DefaultClient,
+		tokenUrl:           defaultTokenUrl,
+		maxAttempts:        1,
+		cacheTTL:           defaultCacheTTL,
+		negativeCacheTTL:   defaultNegativeCacheTTL,
 	}
 
-	actual := NewSearcher("EE")
+	actual := NewSearcher()
 
 	if !reflect.DeepEqual(expected, actual) {
 		t.Errorf("Searcher not matching expected value.\nExpected: %#v\nActual: %#v", expected, actual)
 	}
 }
-*/
+
+func TestNewSearcherWithOptions(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+
+	expected := &Searcher{
+		market:             "EE",
+		baseUrl:            "http://localhost:8080/v1",
+		trackSearchBaseUrl: "http://localhost:8080/v1/search/?type=track&q=",
+		limit:              10,
+		userAgent:          "tester/1.0",
+		client:             client,
+		tokenUrl:           defaultTokenUrl,
+		maxAttempts:        3,
+		retryBaseDelay:     time.Millisecond,
+		cacheTTL:           defaultCacheTTL,
+		negativeCacheTTL:   defaultNegativeCacheTTL,
+	}
+
+	actual := NewSearcher(
+		WithMarket(" ee"),
+		WithBaseURL("http://localhost:8080/v1/"),
+		WithLimit(10),
+		WithUserAgent("tester/1.0"),
+		WithHTTPClient(client),
+		WithRetry(3, time.Millisecond),
+	)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Searcher not matching expected value.\nExpected: %#v\nActual: %#v", expected, actual)
+	}
+}
+
+func TestWithLimitIsKeptWithinPageSize(t *testing.T) {
+	if s := NewSearcher(WithLimit(500)); s.limit != maxSearchLimit {
+		t.Errorf("Expected limit to be %d. Got: %d", maxSearchLimit, s.limit)
+	}
+
+	if s := NewSearcher(WithLimit(-3)); s.limit != 1 {
+		t.Errorf("Expected limit to be 1. Got: %d", s.limit)
+	}
+}
+
+func TestSearcherUsesClientAndUserAgent(t *testing.T) {
+	var userAgent, limit string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		limit = r.URL.Query().Get("limit")
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	s := NewSearcher(WithBaseURL(ts.URL), WithHTTPClient(ts.Client()), WithUserAgent("tester/1.0"), WithLimit(5))
+
+	s.FindClosestMatch("Human Behaviour", "Björk", "")
+
+	if userAgent != "tester/1.0" {
+		t.Errorf("Unexpected User-Agent. Expected: tester/1.0, got: %s", userAgent)
+	}
+
+	if limit != "5" {
+		t.Errorf("Unexpected limit. Expected: 5, got: %s", limit)
+	}
+}
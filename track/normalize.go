@@ -0,0 +1,210 @@
+package track
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeRule rewrites a title, artist or album name into a form that is
+// easier to compare with other names.
+type NormalizeRule func(string) string
+
+// Normalizer applies a list of NormalizeRules to names, in order. A Searcher
+// given a Normalizer with WithNormalizer normalizes the title, artist and
+// album it searches for before building the search queries, and normalizes
+// both sides when comparing them with search results.
+type Normalizer struct {
+	rules []NormalizeRule
+}
+
+// NewNormalizer returns a Normalizer applying rules in the order they are given.
+func NewNormalizer(rules ...NormalizeRule) *Normalizer {
+	return &Normalizer{rules: rules}
+}
+
+// DefaultNormalizer applies every rule of this package, so that for instance
+// "Human Behaviour (2011 Remaster)" and "HUMAN BEHAVIOUR" normalize alike, and
+// so do "Björk feat. Someone" and "Bjork".
+var DefaultNormalizer = NewNormalizer(
+	FoldCompatibility,
+	FoldPunctuation,
+	RemoveFeaturedArtists,
+	RemoveVersionTags,
+	StripDiacritics,
+	FoldCase,
+	CollapseWhitespace,
+)
+
+// Normalize returns s with the rules of n applied. A nil Normalizer returns s
+// unchanged.
+func (n *Normalizer) Normalize(s string) string {
+	if n == nil {
+		return s
+	}
+
+	for _, rule := range n.rules {
+		s = rule(s)
+	}
+
+	return s
+}
+
+// FoldCompatibility replaces characters that are only variants of others, by
+// applying Unicode compatibility decomposition (NFKD) followed by canonical
+// composition, that is normalization form NFKC. Ligatures such as "ﬁ" and "ǆ" are
+// split into their letters, fullwidth, circled and superscript forms become
+// plain letters and digits, "㎏" becomes "kg" and the various spaces become
+// plain spaces, while letters with marks such as "ö" are kept as they are.
+func FoldCompatibility(s string) string {
+	return norm.NFKC.String(s)
+}
+
+// FoldPunctuation replaces typographic quotes, apostrophes and dashes with
+// their ASCII counterparts.
+func FoldPunctuation(s string) string {
+	return mapRunes(s, func(r rune) (string, bool) {
+		switch r {
+		case '‘', '’', '‚', '‛', '′', '`', '´':
+			return "'", true
+		case '“', '”', '„', '‟', '″', '«', '»':
+			return `"`, true
+		case '‐', '‑', '‒', '–', '—', '―', '−':
+			return "-", true
+		}
+
+		return "", false
+	})
+}
+
+// StripDiacritics removes accents and other marks from letters, so that "Björk"
+// and "Hồ Ngọc Hà" become "Bjork" and "Ho Ngoc Ha". The string is decomposed
+// with Unicode compatibility decomposition (NFKD), the nonspacing marks
+// (category Mn) are dropped, and what is left is composed again. Letters such
+// as "ß", "æ", "ø" and "ł", which have no decomposition, are spelled out in
+// plain Latin letters.
+func StripDiacritics(s string) string {
+	stripped := mapRunes(norm.NFKD.String(s), func(r rune) (string, bool) {
+		if unicode.Is(unicode.Mn, r) {
+			return "", true
+		}
+
+		base, found := diacriticFolds[r]
+
+		return base, found
+	})
+
+	return norm.NFC.String(stripped)
+}
+
+// diacriticFolds maps the Latin letters with marks that Unicode does not
+// decompose to their base letters.
+var diacriticFolds = map[rune]string{
+	'Đ': "D", 'đ': "d", 'Ð': "D", 'ð': "d",
+	'Ħ': "H", 'ħ': "h",
+	'ı': "i",
+	'Ł': "L", 'ł': "l",
+	'Ø': "O", 'ø': "o",
+	'Ŧ': "T", 'ŧ': "t",
+	'Æ': "AE", 'æ': "ae",
+	'Œ': "OE", 'œ': "oe",
+	'Þ': "TH", 'þ': "th",
+	'ß': "ss",
+}
+
+// FoldCase turns s into lower case.
+func FoldCase(s string) string {
+	return strings.ToLower(s)
+}
+
+// CollapseWhitespace trims s and replaces every run of whitespace in it with
+// a single space.
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// RemoveVersionTags removes the version tags found by SplitVersionTags.
+func RemoveVersionTags(s string) string {
+	name, _ := SplitVersionTags(s)
+
+	return name
+}
+
+// RemoveFeaturedArtists removes the featured artists found by
+// SplitFeaturedArtists.
+func RemoveFeaturedArtists(s string) string {
+	name, _ := SplitFeaturedArtists(s)
+
+	return name
+}
+
+// versionKeywords are the words telling that a part of a title names a
+// version of a recording rather than being part of the title.
+const versionKeywords = `remaster|remastered|live|mono|stereo|version|edit|mix|remix|deluxe|demo|acoustic|bonus|anniversary|expanded|single|instrumental|explicit|clean`
+
+var (
+	bracketedVersionTag = regexp.MustCompile(`(?i)\s*[(\[]([^()\[\]]*\b(?:` + versionKeywords + `)\b[^()\[\]]*)[)\]]`)
+	dashedVersionTag    = regexp.MustCompile(`(?i)\s+-\s+(.*\b(?:` + versionKeywords + `)\b.*)$`)
+)
+
+// SplitVersionTags returns s without the tags naming a version of a
+// recording, such as "(2011 Remaster)", "[Live]" or " - Radio Edit", and the
+// tags without their brackets or dash.
+func SplitVersionTags(s string) (string, []string) {
+	var tags []string
+
+	for _, pattern := range []*regexp.Regexp{bracketedVersionTag, dashedVersionTag} {
+		for _, match := range pattern.FindAllStringSubmatch(s, -1) {
+			tags = append(tags, strings.TrimSpace(match[1]))
+		}
+
+		s = pattern.ReplaceAllString(s, "")
+	}
+
+	return strings.TrimSpace(s), tags
+}
+
+var (
+	bracketedFeaturedArtists = regexp.MustCompile(`(?i)\s*[(\[](?:feat\.?|ft\.?|featuring)\s+([^()\[\]]+)[)\]]`)
+	featuredArtists          = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring)\s+(.+)$`)
+	featuredArtistSeparator  = regexp.MustCompile(`\s*[,&]\s*`)
+)
+
+// SplitFeaturedArtists returns s without the artists it credits as featured,
+// such as in "Song (feat. Someone)" or "Artist ft. Someone & Someone Else",
+// and the names of the featured artists.
+func SplitFeaturedArtists(s string) (string, []string) {
+	var artists []string
+
+	for _, pattern := range []*regexp.Regexp{bracketedFeaturedArtists, featuredArtists} {
+		for _, match := range pattern.FindAllStringSubmatch(s, -1) {
+			for _, name := range featuredArtistSeparator.Split(match[1], -1) {
+				if name = strings.TrimSpace(name); name != "" {
+					artists = append(artists, name)
+				}
+			}
+		}
+
+		s = pattern.ReplaceAllString(s, "")
+	}
+
+	return strings.TrimSpace(s), artists
+}
+
+// mapRunes returns s with every rune for which replace returns true replaced
+// by the string it returns.
+func mapRunes(s string, replace func(rune) (string, bool)) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		if replacement, replaced := replace(r); replaced {
+			b.WriteString(replacement)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
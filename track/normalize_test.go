@@ -0,0 +1,170 @@
+package track
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testRule checks that rule turns every key of cases into its value.
+func testRule(t *testing.T, name string, rule NormalizeRule, cases map[string]string) {
+	for input, expected := range cases {
+		if actual := rule(input); expected != actual {
+			t.Errorf("Unexpected result of %s(%q).\nExpected: %q\nActual:   %q", name, input, expected, actual)
+		}
+	}
+}
+
+func TestFoldCompatibility(t *testing.T) {
+	testRule(t, "FoldCompatibility", FoldCompatibility, map[string]string{
+		"ﬁnal ﬂight": "final flight",
+		"ＡＢＣ１２３":     "ABC123",
+		"x² y₃":      "x2 y3",
+		"Wait…":      "Wait...",
+		"Björk":      "Björk",
+		"Track　One™": "Track OneTM",
+		"ǆ ㎏ Ⓐ":      "dž kg A",
+		"½":          "1⁄2",
+	})
+}
+
+func TestFoldPunctuation(t *testing.T) {
+	testRule(t, "FoldPunctuation", FoldPunctuation, map[string]string{
+		"Don’t Stop":      "Don't Stop",
+		"“Heroes”":        `"Heroes"`,
+		"Pt. 1 – Pt. 2":   "Pt. 1 - Pt. 2",
+		"plain 'ascii' -": "plain 'ascii' -",
+	})
+}
+
+func TestStripDiacritics(t *testing.T) {
+	testRule(t, "StripDiacritics", StripDiacritics, map[string]string{
+		"Björk":              "Bjork",
+		"Sigur Rós":          "Sigur Ros",
+		"Mötley Crüe":        "Motley Crue",
+		"Straße":             "Strasse",
+		"Æther Øresund Łódź": "AEther Oresund Lodz",
+		"Beyoncé":           "Beyonce",
+		"Ça plane pour moi":  "Ca plane pour moi",
+		"Bjo\u0308rk":        "Bjork",
+		"Hồ Ngọc Hà":         "Ho Ngoc Ha",
+		"Đorđe Balašević":    "Dorde Balasevic",
+		"가나다":                "가나다",
+	})
+}
+
+func TestFoldCase(t *testing.T) {
+	testRule(t, "FoldCase", FoldCase, map[string]string{
+		"HUMAN Behaviour": "human behaviour",
+		"ÖÄÅ":             "öäå",
+	})
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	testRule(t, "CollapseWhitespace", CollapseWhitespace, map[string]string{
+		"  Human \t Behaviour\n": "Human Behaviour",
+		"":                       "",
+	})
+}
+
+func TestSplitVersionTags(t *testing.T) {
+	cases := []struct {
+		input, name string
+		tags        []string
+	}{
+		{"Song (2011 Remaster)", "Song", []string{"2011 Remaster"}},
+		{"Song [Live] (Mono Version)", "Song", []string{"Live", "Mono Version"}},
+		{"Song - Radio Edit", "Song", []string{"Radio Edit"}},
+		{"Song - 2011 Remastered Version", "Song", []string{"2011 Remastered Version"}},
+		{"Song (Part 2)", "Song (Part 2)", nil},
+		{"Hyper-Ballad", "Hyper-Ballad", nil},
+		{"Live Forever", "Live Forever", nil},
+		{"Deliver (Live)", "Deliver", []string{"Live"}},
+	}
+
+	for _, c := range cases {
+		name, tags := SplitVersionTags(c.input)
+
+		if name != c.name || !reflect.DeepEqual(tags, c.tags) {
+			t.Errorf("Unexpected result of SplitVersionTags(%q).\nExpected: %q, %q\nActual:   %q, %q", c.input, c.name, c.tags, name, tags)
+		}
+	}
+}
+
+func TestSplitFeaturedArtists(t *testing.T) {
+	cases := []struct {
+		input, name string
+		artists     []string
+	}{
+		{"Artist feat. X", "Artist", []string{"X"}},
+		{"Artist ft. X & Y", "Artist", []string{"X", "Y"}},
+		{"Song (feat. X, Y)", "Song", []string{"X", "Y"}},
+		{"Song [Featuring X] (Live)", "Song (Live)", []string{"X"}},
+		{"Feather", "Feather", nil},
+		{"Soft Cell", "Soft Cell", nil},
+	}
+
+	for _, c := range cases {
+		name, artists := SplitFeaturedArtists(c.input)
+
+		if name != c.name || !reflect.DeepEqual(artists, c.artists) {
+			t.Errorf("Unexpected result of SplitFeaturedArtists(%q).\nExpected: %q, %q\nActual:   %q, %q", c.input, c.name, c.artists, name, artists)
+		}
+	}
+}
+
+func TestDefaultNormalizer(t *testing.T) {
+	testRule(t, "DefaultNormalizer.Normalize", DefaultNormalizer.Normalize, map[string]string{
+		"Human  Behaviour (2011 Remaster)": "human behaviour",
+		"Björk feat. Someone":              "bjork",
+		"Don’t Stop Me Now – Live":         "don't stop me now",
+		"Hồ Ngọc Hà":                       "ho ngoc ha",
+	})
+
+	var n *Normalizer
+
+	if actual := n.Normalize(" Björk "); actual != " Björk " {
+		t.Errorf("Expected a nil Normalizer to leave names unchanged. Got: %q", actual)
+	}
+
+	custom := NewNormalizer(StripDiacritics, FoldCase)
+
+	if actual := custom.Normalize("BJÖRK (Live)"); actual != "bjork (live)" {
+		t.Errorf("Unexpected result of a custom Normalizer. Got: %q", actual)
+	}
+}
+
+func TestFindNormalizesQueries(t *testing.T) {
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		w.Write([]byte(oneTrackJSON))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithNormalizer(DefaultNormalizer))
+
+	s.Find("Human Behaviour (2011 Remaster)", "Björk feat. Someone", "")
+	s.Find("(Live)", "Björk", "")
+
+	expected := []string{`track:"human behaviour" artist:"bjork"`, `track:"(Live)" artist:"bjork"`}
+
+	if !reflect.DeepEqual(expected, queries) {
+		t.Errorf("Unexpected queries.\nExpected: %q\nActual:   %q", expected, queries)
+	}
+}
+
+func TestScoreWithNormalizer(t *testing.T) {
+	candidate := Track{Name: "Human Behaviour", Artists: []Artist{{Name: "Bjork"}}}
+	target := matchTarget{title: "HUMAN  BEHAVIOUR", artist: "Björk"}
+
+	if actual := (matcher{normalizer: DefaultNormalizer}).score(candidate, target).Confidence; actual != 1 {
+		t.Errorf("Expected normalized names to match exactly. Got: %v", actual)
+	}
+
+	if actual := (matcher{}).score(candidate, target).Confidence; actual == 1 {
+		t.Error("Expected names not to match exactly without a normalizer.")
+	}
+}
@@ -0,0 +1,170 @@
+package track
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Searcher created by NewSearcher.
+type Option func(*Searcher)
+
+// WithHTTPClient makes the Searcher send its requests with client, which allows
+// setting timeouts, proxies or a custom transport. A nil client is ignored.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Searcher) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithBaseURL makes the Searcher use the API at baseUrl rather than
+// "https://api.spotify.com/v1", for instance a local stand-in server.
+func WithBaseURL(baseUrl string) Option {
+	return func(s *Searcher) {
+		s.baseUrl = strings.TrimRight(strings.TrimSpace(baseUrl), "/")
+		s.trackSearchBaseUrl = s.baseUrl + trackSearchPath
+	}
+}
+
+// WithMarket makes the Searcher search for tracks playable in market, an
+// ISO 3166-1 alpha-2 country code such as "SE".
+func WithMarket(market string) Option {
+	return func(s *Searcher) {
+		s.market = strings.ToUpper(strings.TrimSpace(market))
+	}
+}
+
+// WithLimit sets the number of search results FindClosestMatch picks the best
+// match from, and the number of tracks FindAll returns when no limit is passed
+// to it. The limit is kept between 1 and the largest page size of the API.
+func WithLimit(limit int) Option {
+	return func(s *Searcher) {
+		s.limit = max(1, min(limit, maxSearchLimit))
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests made by the Searcher.
+func WithUserAgent(userAgent string) Option {
+	return func(s *Searcher) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithClientCredentials makes the Searcher authorize its requests with access
+// tokens fetched using the client credentials flow, with the client id and
+// secret of a Spotify application. Tokens are cached until shortly before they
+// expire.
+func WithClientCredentials(clientId, clientSecret string) Option {
+	return func(s *Searcher) {
+		s.tokens = newTokenSource(clientId, clientSecret)
+	}
+}
+
+// WithTokenURL makes the Searcher fetch access tokens from tokenUrl rather
+// than "https://accounts.spotify.com/api/token". It has no effect unless
+// WithClientCredentials is also given.
+func WithTokenURL(tokenUrl string) Option {
+	return func(s *Searcher) {
+		s.tokenUrl = tokenUrl
+	}
+}
+
+// WithRetry makes the Searcher send a request up to maxAttempts times when it
+// is rate limited or fails with a server error. The wait between attempts
+// starts at baseDelay and doubles with every attempt up to a minute, with random
+// jitter, but it is never shorter than the Retry-After asked for by Spotify. A
+// baseDelay that is not positive means retrying at once, unless Spotify asks
+// for a wait.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(s *Searcher) {
+		s.maxAttempts = max(1, maxAttempts)
+		s.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRateLimiter makes the Searcher wait for limiter before every request it
+// sends to the API. The same limiter may be given to several Searchers.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(s *Searcher) {
+		s.limiter = limiter
+	}
+}
+
+// WithLogHandler makes the Searcher log its requests, the steps of its searches
+// and the tracks it finds to handler. Requests and search steps are logged at
+// debug level, found tracks at info level, and failed requests and retries at
+// warning or error level. By default nothing is logged.
+func WithLogHandler(handler slog.Handler) Option {
+	return func(s *Searcher) {
+		s.logHandler = handler
+	}
+}
+
+// WithNormalizer makes the Searcher normalize the title, artist and album it
+// searches for with n before building the search queries, and normalize the
+// names of the tracks found with n too before comparing them. DefaultNormalizer
+// suits most uses. By default names are only trimmed.
+func WithNormalizer(n *Normalizer) Option {
+	return func(s *Searcher) {
+		s.normalizer = n
+	}
+}
+
+// WithMatchWeights sets the weights of the parts of the confidence of the
+// matches found by the Searcher, which otherwise are DefaultMatchWeights.
+// Negative weights are taken as zero, and weights giving none of the title,
+// artist, album and duration a positive weight are ignored, since they leave
+// nothing to score.
+func WithMatchWeights(weights MatchWeights) Option {
+	return func(s *Searcher) {
+		weights = MatchWeights{
+			Title:      max(0, weights.Title),
+			Artist:     max(0, weights.Artist),
+			Album:      max(0, weights.Album),
+			Duration:   max(0, weights.Duration),
+			VersionTag: max(0, weights.VersionTag),
+		}
+
+		if weights.Title+weights.Artist+weights.Album+weights.Duration > 0 {
+			s.matchWeights = weights
+		}
+	}
+}
+
+// WithCache makes the Searcher keep the responses from the API in cache, and
+// use them rather than sending the same request again until they expire. A
+// nil cache gives an in-memory LRUCache. Expired responses are revalidated
+// with their ETag, if they have one.
+func WithCache(cache Cache) Option {
+	return func(s *Searcher) {
+		if cache == nil {
+			cache = NewLRUCache(defaultCacheSize)
+		}
+
+		s.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long cached responses are used before they expire.
+// Search responses without any tracks expire after negativeTTL instead. A ttl
+// that is not positive means that such responses are not cached at all.
+func WithCacheTTL(ttl, negativeTTL time.Duration) Option {
+	return func(s *Searcher) {
+		s.cacheTTL = ttl
+		s.negativeCacheTTL = negativeTTL
+	}
+}
+
+// WithMatchCache makes Find keep the tracks it finds in cache, keyed by the
+// title, artist and album searched for and the market of the Searcher, and
+// return them without searching again until they expire. Cached tracks
+// expire after the ttl set by WithCacheTTL. A DiskCache makes the found
+// tracks survive across runs.
+func WithMatchCache(cache Cache) Option {
+	return func(s *Searcher) {
+		s.matchCache = cache
+	}
+}
@@ -0,0 +1,242 @@
+package track
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Query builds a Spotify search query from field filters, such as
+//
+//	Query{}.Track("Human Behaviour").Artist("Björk").YearRange(1990, 1999)
+//
+// Every method returns a new Query with a filter added, leaving the Query it
+// is called on unchanged, so a Query can be used as the base of several
+// others. Names are quoted with quoteSearchValue, so that they are searched
+// for as they are, and names without any words are left out, so
+// that optional fields can be passed as they come.
+//
+// An invalid argument, such as a malformed ISRC, is reported by Encode and by
+// the methods of Searcher taking the Query.
+type Query struct {
+	terms []string
+	err   error
+}
+
+// Track returns q restricted to tracks named name.
+func (q Query) Track(name string) Query {
+	return q.withValue("track:", name)
+}
+
+// Artist returns q restricted to tracks by an artist named name.
+func (q Query) Artist(name string) Query {
+	return q.withValue("artist:", name)
+}
+
+// Album returns q restricted to tracks on an album named name.
+func (q Query) Album(name string) Query {
+	return q.withValue("album:", name)
+}
+
+// Genre returns q restricted to tracks by artists of the genre genre.
+func (q Query) Genre(genre string) Query {
+	return q.withValue("genre:", genre)
+}
+
+// Text returns q restricted to results matching text in any field.
+func (q Query) Text(text string) Query {
+	return q.withValue("", text)
+}
+
+// Year returns q restricted to tracks released in year.
+func (q Query) Year(year int) Query {
+	return q.YearRange(year, year)
+}
+
+// YearRange returns q restricted to tracks released from the year from to
+// the year to, both included, such as a decade with YearRange(1990, 1999).
+func (q Query) YearRange(from, to int) Query {
+	if from < 1 || to > 9999 || from > to {
+		return q.withError(fmt.Sprintf("%d-%d is not a valid range of years.", from, to))
+	}
+
+	if from == to {
+		return q.with(fmt.Sprintf("year:%d", from))
+	}
+
+	return q.with(fmt.Sprintf("year:%d-%d", from, to))
+}
+
+// ISRC returns q restricted to tracks with the International Standard
+// Recording Code isrc, such as "GBBTF9300001". Hyphens in isrc are ignored.
+// An ISRC is a two letter country code, a three character registrant code, two
+// digits for the year and a five digit designation code.
+func (q Query) ISRC(isrc string) Query {
+	isrc = strings.ToUpper(strings.Replace(strings.TrimSpace(isrc), "-", "", -1))
+
+	if !isValidIsrc(isrc) {
+		return q.withError(fmt.Sprintf("%q is not a valid ISRC.", isrc))
+	}
+
+	return q.with("isrc:" + isrc)
+}
+
+// UPC returns q restricted to albums with the Universal Product Code upc,
+// a barcode of 12 or 13 digits.
+func (q Query) UPC(upc string) Query {
+	upc = strings.TrimSpace(upc)
+
+	if (len(upc) != 12 && len(upc) != 13) || strings.Trim(upc, "0123456789") != "" {
+		return q.withError(fmt.Sprintf("%q is not a valid UPC.", upc))
+	}
+
+	return q.with("upc:" + upc)
+}
+
+// TagNew returns q restricted to albums released in the past two weeks.
+func (q Query) TagNew() Query {
+	return q.with("tag:new")
+}
+
+// TagHipster returns q restricted to albums with the lowest 10% popularity.
+func (q Query) TagHipster() Query {
+	return q.with("tag:hipster")
+}
+
+// Not returns q excluding the results matching any of the filters of other,
+// such as Query{}.Track("Human Behaviour").Not(Query{}.Text("live")). Other
+// must have at least one filter, and only plain filters, since Spotify has no
+// way of negating an OR or a NOT as a whole.
+func (q Query) Not(other Query) Query {
+	if q.err == nil {
+		q.err = other.err
+	}
+
+	if len(other.terms) == 0 {
+		return q.withError("NOT must have a filter.")
+	}
+
+	for _, term := range other.terms {
+		if term == "OR" || strings.HasPrefix(term, "NOT ") {
+			return q.withError("NOT only takes plain filters, not OR or NOT.")
+		}
+	}
+
+	for _, term := range other.terms {
+		q = q.with("NOT " + term)
+	}
+
+	return q
+}
+
+// Or returns a query matching the results of either q or other. Spotify
+// binds OR to the filters next to it, so q and other should have a single
+// filter each, as in Query{}.Year(1993).Or(Query{}.Year(1995)), for their
+// combination to mean what it says.
+func (q Query) Or(other Query) Query {
+	if q.err == nil {
+		q.err = other.err
+	}
+
+	if len(q.terms) == 0 || len(other.terms) == 0 {
+		return q.withError("Both sides of OR must have a filter.")
+	}
+
+	q = q.with("OR")
+
+	for _, term := range other.terms {
+		q = q.with(term)
+	}
+
+	return q
+}
+
+// String returns the query as it is searched for, before it is escaped for
+// the url.
+func (q Query) String() string {
+	return strings.Join(q.terms, " ")
+}
+
+// Encode returns the query escaped for the q parameter of a search url. If
+// an invalid argument was passed to the Query, or it has no filters, a
+// TrackError with ErrorType ArgumentError is returned.
+func (q Query) Encode() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+
+	if len(q.terms) == 0 {
+		return "", TrackError{Msg: "The search query is empty.", ErrorType: ArgumentError}
+	}
+
+	return url.QueryEscape(q.String()), nil
+}
+
+// with returns a copy of q with term added, not sharing its terms with q.
+func (q Query) with(term string) Query {
+	q.terms = append(q.terms[:len(q.terms):len(q.terms)], term)
+
+	return q
+}
+
+// withValue returns a copy of q with the quoted value added after field,
+// or q itself if value has no words.
+func (q Query) withValue(field, value string) Query {
+	if searchValue(value) == "" {
+		return q
+	}
+
+	return q.with(field + quoteSearchValue(value))
+}
+
+// withError returns a copy of q failing with msg, unless it has failed
+// already.
+func (q Query) withError(msg string) Query {
+	if q.err == nil {
+		q.err = TrackError{Msg: msg, ErrorType: ArgumentError}
+	}
+
+	return q
+}
+
+// Search returns the tracks from Spotify matching q, in the order Spotify ranks
+// them, together with the total number of tracks matching it, like FindAll
+// does for its queries. The tracks have QueryLevel NoQueryLevel, and
+// opts.QueryLevel is ignored. Since a Query has nothing to compare the tracks
+// with, they are not scored, and their Match is the zero Match. If q is invalid, a TrackError with ErrorType
+// ArgumentError is returned.
+func (s Searcher) Search(q Query, opts FindAllOptions) (FindAllResult, error) {
+	return s.SearchContext(context.Background(), q, opts)
+}
+
+// SearchContext is like Search, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before the search is done, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) SearchContext(ctx context.Context, q Query, opts FindAllOptions) (FindAllResult, error) {
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return FindAllResult{}, TrackError{Msg: "Offset and limit passed to Search must not be negative.", ErrorType: ArgumentError}
+	}
+
+	searchQuery, err := q.Encode()
+
+	if err != nil {
+		return FindAllResult{}, err
+	}
+
+	limit := opts.Limit
+
+	if limit == 0 {
+		limit = s.limitOr(defaultFindAllLimit)
+	}
+
+	url := s.searchUrl(searchQuery, min(limit, maxSearchLimit))
+
+	if opts.Offset > 0 {
+		url += fmt.Sprintf("&offset=%d", opts.Offset)
+	}
+
+	s.logSearchStep(ctx, "Search", 0, NoQueryLevel, searchQuery)
+
+	return s.collectTracks(ctx, url, opts.Offset, limit, NoQueryLevel, nil)
+}
@@ -0,0 +1,156 @@
+package track
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/joarleth/spotify/track/tracktest"
+)
+
+func TestQueryString(t *testing.T) {
+	cases := []struct {
+		query    Query
+		expected string
+	}{
+		{Query{}.Track("Human Behaviour").Artist("Björk"), `track:"Human Behaviour" artist:"Björk"`},
+		{Query{}.Track(" Venus ").Artist("").Album("Debut"), `track:"Venus" album:"Debut"`},
+		{Query{}.Album(`12" Singles`), `album:"12 Singles"`},
+		{Query{}.Artist("Björk").YearRange(1990, 1999), `artist:"Björk" year:1990-1999`},
+		{Query{}.Artist("Björk").Year(1993), `artist:"Björk" year:1993`},
+		{Query{}.Genre("art pop").Text("behaviour"), `genre:"art pop" "behaviour"`},
+		{Query{}.ISRC("gb-btf-93-00001"), `isrc:GBBTF9300001`},
+		{Query{}.UPC("0042284015123"), `upc:0042284015123`},
+		{Query{}.Artist("Björk").TagNew().TagHipster(), `artist:"Björk" tag:new tag:hipster`},
+		{Query{}.Track("Army of Me").Not(Query{}.Text("live").Album("Post")), `track:"Army of Me" NOT "live" NOT album:"Post"`},
+		{Query{}.Year(1993).Or(Query{}.Year(1995)), `year:1993 OR year:1995`},
+	}
+
+	for _, c := range cases {
+		if actual := c.query.String(); c.expected != actual {
+			t.Errorf("Unexpected query.\nExpected: %s\nActual:   %s", c.expected, actual)
+		}
+
+		encoded, err := c.query.Encode()
+
+		if err != nil {
+			t.Errorf("Expected error to be nil. Got: %s", err.Error())
+		}
+
+		if encoded != url.QueryEscape(c.expected) {
+			t.Errorf("Unexpected encoded query.\nExpected: %s\nActual:   %s", url.QueryEscape(c.expected), encoded)
+		}
+	}
+}
+
+func TestQueryIsNotChangedByDerivedQueries(t *testing.T) {
+	base := Query{}.Track("Human Behaviour")
+	withArtist := base.Artist("Björk")
+	withAlbum := base.Album("Debut")
+
+	if base.String() != `track:"Human Behaviour"` {
+		t.Errorf("Expected the base query to be unchanged. Got: %s", base.String())
+	}
+
+	if withArtist.String() != `track:"Human Behaviour" artist:"Björk"` || withAlbum.String() != `track:"Human Behaviour" album:"Debut"` {
+		t.Errorf("Unexpected derived queries: %s, %s", withArtist.String(), withAlbum.String())
+	}
+}
+
+func TestQueryInvalidArguments(t *testing.T) {
+	queries := []Query{
+		{},
+		Query{}.Track(" "),
+		Query{}.YearRange(1999, 1990),
+		Query{}.Year(0),
+		Query{}.ISRC("GBBTF93"),
+		Query{}.UPC("12345"),
+		Query{}.UPC("12345678901a"),
+		Query{}.Track("a").Not(Query{}.ISRC("x")),
+		Query{}.Track("a").Not(Query{}),
+		Query{}.Track("a").Not(Query{}.Track(" ")),
+		Query{}.Track("Song").Not(Query{}.Year(1993).Or(Query{}.Year(1995))),
+		Query{}.Track("Song").Not(Query{}.Not(Query{}.Year(1993))),
+		Query{}.Or(Query{}.Year(1993)),
+		Query{}.Year(1993).Or(Query{}),
+	}
+
+	for _, q := range queries {
+		if _, err := q.Encode(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Expected error of %q to be ErrInvalidArgument. Got: %v", q.String(), err)
+		}
+	}
+}
+
+func TestSearchWithinDecade(t *testing.T) {
+	bjork := []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Björk"}}
+
+	server := tracktest.NewServer(
+		tracktest.Track{Id: "4ry6oqlwdsooYtniYJFkt5", Name: "Human Behaviour", Artists: bjork, Album: tracktest.Album{Id: "3icOCBqUqz5JJl9ajZzBwf", Name: "Debut", ReleaseDate: "1993-07-05"}},
+		tracktest.Track{Id: "1dvSjb2WCiQhR0KbrqvHK2", Name: "Human Behaviour - Live", Artists: bjork, Album: tracktest.Album{Id: "2DQwm3ZsbpOFnaNTRWA2IF", Name: "Vespertine Live", ReleaseDate: "2003-08-11"}},
+	)
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	result, err := s.Search(Query{}.Track("Human Behaviour").Artist("Björk").YearRange(1990, 1999), FindAllOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if result.Total != 1 || len(result.Tracks) != 1 || result.Tracks[0].Album.Name != "Debut" {
+		t.Errorf("Unexpected tracks: %v", result.Tracks)
+	}
+
+	if _, err := s.Search(Query{}.YearRange(2000, 1990), FindAllOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected error to be ErrInvalidArgument. Got: %v", err)
+	}
+
+	if requests := len(server.Requests()); requests != 1 {
+		t.Errorf("Expected 1 request. Got: %d", requests)
+	}
+}
+
+func TestSearchNotAndOr(t *testing.T) {
+	bjork := []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Björk"}}
+
+	server := tracktest.NewServer(
+		tracktest.Track{Id: "0Ypg6RWDG5b4nZ8MyEDbCg", Name: "Army of Me", Artists: bjork, Album: tracktest.Album{Id: "2cWBwpqMsDJC1ZUwz813lo", Name: "Post", ReleaseDate: "1995-06-13"}},
+		tracktest.Track{Id: "1hn2U7NwSwVQ9Lt2WF1iK6", Name: "Army of Me", Artists: bjork, Album: tracktest.Album{Id: "4hBA7VgOSxsWOf2N9dJv2X", Name: "Live at Shepherds Bush", ReleaseDate: "1997-02-24"}},
+		tracktest.Track{Id: "4ry6oqlwdsooYtniYJFkt5", Name: "Human Behaviour", Artists: bjork, Album: tracktest.Album{Id: "3icOCBqUqz5JJl9ajZzBwf", Name: "Debut", ReleaseDate: "1993-07-05"}},
+	)
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	cases := []struct {
+		query    Query
+		expected []string
+	}{
+		{Query{}.Track("Army of Me").Not(Query{}.Album("Live")), []string{"Post"}},
+		{Query{}.Artist("Björk").Not(Query{}.Track("Army")), []string{"Debut"}},
+		{Query{}.Year(1993).Or(Query{}.Year(1995)), []string{"Post", "Debut"}},
+		{Query{}.Track("Army of Me").Year(1993).Or(Query{}.Year(1997)), []string{"Live at Shepherds Bush"}},
+		{Query{}.Text("NOT").Artist("Björk"), nil},
+	}
+
+	for _, c := range cases {
+		result, err := s.Search(c.query, FindAllOptions{})
+
+		if err != nil {
+			t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+		}
+
+		var albums []string
+
+		for _, track := range result.Tracks {
+			albums = append(albums, track.Album.Name)
+		}
+
+		if !reflect.DeepEqual(albums, c.expected) {
+			t.Errorf("Unexpected albums for %s.\nExpected: %v\nActual:   %v", c.query, c.expected, albums)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package track
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how often requests are sent to Spotify.
+// It holds up to burst tokens and is refilled with rate tokens per second, and
+// every request takes one token, waiting for it if the bucket is empty.
+//
+// A RateLimiter is safe for concurrent use and may be shared between several
+// Searchers, so that they stay under a common quota together.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats tells how much a RateLimiter has held back requests.
+// Requests is the number of requests let through, Delayed the number of
+// them that had to wait and TotalWait the sum of their waits.
+type RateLimiterStats struct {
+	Requests  int
+	Delayed   int
+	TotalWait time.Duration
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate requests per second on
+// average and bursts of up to burst requests. The bucket starts out full.
+// A burst below one is treated as one, and a rate that is not positive
+// means that requests are not limited at all.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Wait takes a token from the bucket, waiting until one is available. If ctx
+// is done before that, the token is given back and a TrackError with ErrorType
+// CanceledError is returned.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if ctxErr := contextError(ctx); ctxErr != nil {
+		return ctxErr
+	}
+
+	wait := rl.reserve()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	if err := sleepContext(ctx, wait); err != nil {
+		rl.mu.Lock()
+		rl.tokens = min(rl.tokens+1, rl.burst)
+		rl.stats.Requests--
+		rl.stats.Delayed--
+		rl.stats.TotalWait -= wait
+		rl.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// reserve takes a token, letting the bucket go negative if it is empty, and
+// returns how long the caller has to wait before the token is really there.
+func (rl *RateLimiter) reserve() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.stats.Requests++
+
+	if rl.rate <= 0 {
+		return 0
+	}
+
+	now := rl.now()
+	rl.tokens = min(rl.tokens+now.Sub(rl.last).Seconds()*rl.rate, rl.burst)
+	rl.last = now
+	rl.tokens--
+
+	if rl.tokens >= 0 {
+		return 0
+	}
+
+	wait := time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+	rl.stats.Delayed++
+	rl.stats.TotalWait += wait
+
+	return wait
+}
+
+// Stats returns how much the RateLimiter has held back requests so far.
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return rl.stats
+}
@@ -0,0 +1,126 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	rl := NewRateLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+		}
+	}
+
+	stats := rl.Stats()
+
+	if stats.Requests != 3 || stats.Delayed != 0 || stats.TotalWait != 0 {
+		t.Errorf("Expected 3 requests let through without waiting. Got: %+v", stats)
+	}
+}
+
+func TestRateLimiterWaitsForRefill(t *testing.T) {
+	rl := NewRateLimiter(100, 1)
+
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		rl.Wait(context.Background())
+	}
+
+	elapsed := time.Since(start)
+
+	if elapsed < 35*time.Millisecond {
+		t.Errorf("Expected 5 requests at 100 per second to take at least 40ms. Took: %v", elapsed)
+	}
+
+	stats := rl.Stats()
+
+	if stats.Requests != 5 || stats.Delayed != 4 || stats.TotalWait <= 0 {
+		t.Errorf("Expected 4 of 5 requests to wait. Got: %+v", stats)
+	}
+}
+
+func TestRateLimiterRefillsOverTime(t *testing.T) {
+	rl := NewRateLimiter(10, 2)
+
+	now := time.Now()
+	rl.last = now
+	rl.now = func() time.Time { return now }
+
+	if wait := rl.reserve(); wait != 0 {
+		t.Errorf("Expected no wait. Got: %v", wait)
+	}
+
+	if wait := rl.reserve(); wait != 0 {
+		t.Errorf("Expected no wait. Got: %v", wait)
+	}
+
+	if wait := rl.reserve(); wait != 100*time.Millisecond {
+		t.Errorf("Expected to wait 100ms. Got: %v", wait)
+	}
+
+	now = now.Add(time.Second)
+
+	if wait := rl.reserve(); wait != 0 {
+		t.Errorf("Expected no wait after refill. Got: %v", wait)
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	rl := NewRateLimiter(0.1, 1)
+	rl.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := rl.Wait(ctx)
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != CanceledError {
+		t.Errorf("Expected a TrackError with ErrorType CanceledError. Got: %v", err)
+	}
+
+	if stats := rl.Stats(); stats.Requests != 1 || stats.Delayed != 0 {
+		t.Errorf("Expected the cancelled request not to be counted. Got: %+v", stats)
+	}
+}
+
+func TestRateLimiterSharedBetweenSearchers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(noTracksJSON))
+	}))
+	defer ts.Close()
+
+	rl := NewRateLimiter(20, 2)
+	first := newMockSearcher(ts.URL, WithRateLimiter(rl))
+	second := newMockSearcher(ts.URL, WithRateLimiter(rl))
+
+	var wg sync.WaitGroup
+
+	for _, s := range []*Searcher{first, second, first, second} {
+		wg.Add(1)
+
+		go func(s *Searcher) {
+			defer wg.Done()
+			s.Find("Human Behaviour", "Björk", "")
+		}(s)
+	}
+
+	wg.Wait()
+
+	stats := rl.Stats()
+
+	if stats.Requests != 4 {
+		t.Errorf("Expected 4 requests. Got: %d", stats.Requests)
+	}
+
+	if stats.Delayed != 2 {
+		t.Errorf("Expected 2 requests to wait. Got: %d", stats.Delayed)
+	}
+}
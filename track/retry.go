@@ -0,0 +1,88 @@
+package track
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryDelay caps the exponential backoff between retried requests.
+// A longer Retry-After asked for by Spotify is still respected.
+const maxRetryDelay = time.Minute
+
+// isRetryable tells whether a request failing with err may succeed if it is
+// sent again, that is if it was rate limited or failed with a server error.
+func isRetryable(err error) bool {
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		return false
+	}
+
+	return terr.ErrorType == RateLimitError || terr.StatusCode >= http.StatusInternalServerError
+}
+
+// retryDelay returns how long to wait before the next attempt after attempt
+// failed with err. The delay doubles with every attempt, starting at the base
+// delay of the Searcher, and is jittered to between half and all of that so
+// that concurrent callers spread out. A base delay that is not positive gives
+// no delay. It is never shorter than the Retry-After of a rate limited request.
+func (s Searcher) retryDelay(attempt int, err error) time.Duration {
+	delay := max(0, s.retryBaseDelay)
+
+	// Doubling stops at maxRetryDelay, so that the delay cannot overflow.
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, maxRetryDelay)
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if terr, isTrackError := err.(TrackError); isTrackError && terr.RetryAfter > delay {
+		delay = terr.RetryAfter
+	}
+
+	return delay
+}
+
+// parseRetryAfter returns the duration of a Retry-After header, which is
+// either a number of seconds or an HTTP date. An empty or malformed header
+// gives zero.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// sleepContext waits for d, or returns a TrackError with ErrorType
+// CanceledError if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
@@ -0,0 +1,205 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTooManyRequestsReturnsRateLimitError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	terr, isTrackError := err.(TrackError)
+
+	if !isTrackError {
+		t.Fatalf("Expected error to be of type TrackError. Got: %v", err)
+	}
+
+	if terr.ErrorType != RateLimitError {
+		t.Error("Expected ErrorType to be RateLimitError.")
+	}
+
+	if terr.RetryAfter != 7*time.Second {
+		t.Errorf("Expected RetryAfter to be 7s. Got: %v", terr.RetryAfter)
+	}
+
+	if terr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected StatusCode to be %d. Got: %d", http.StatusTooManyRequests, terr.StatusCode)
+	}
+}
+
+func TestForbiddenIsNotRateLimitError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != ExternalServiceError {
+		t.Errorf("Expected a TrackError with ErrorType ExternalServiceError. Got: %v", err)
+	}
+}
+
+func TestRetryOnRateLimitAndServerError(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "", http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, "", http.StatusBadGateway)
+		default:
+			w.Write([]byte(oneTrackJSON))
+		}
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithRetry(3, time.Millisecond))
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if err != nil {
+		t.Errorf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests. Got: %d", requests)
+	}
+}
+
+func TestRetryGivesUpWhenAttemptsAreSpent(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithRetry(2, time.Millisecond))
+
+	_, err := s.Find("Human Behaviour", "Björk", "")
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a TrackError with StatusCode 503. Got: %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests. Got: %d", requests)
+	}
+}
+
+func TestRetryDoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithRetry(3, time.Millisecond))
+
+	s.Find("Human Behaviour", "Björk", "")
+
+	if requests != 1 {
+		t.Errorf("Expected a single request. Got: %d", requests)
+	}
+}
+
+func TestRetryWaitRespectsContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := s.FindContext(ctx, "Human Behaviour", "Björk", "")
+
+	if terr, isTrackError := err.(TrackError); !isTrackError || terr.ErrorType != CanceledError {
+		t.Errorf("Expected a TrackError with ErrorType CanceledError. Got: %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	s := NewSearcher(WithRetry(5, 100*time.Millisecond))
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		delay := s.retryDelay(attempt+1, TrackError{ErrorType: ExternalServiceError, StatusCode: 500})
+
+		if delay < max/2 || delay > max {
+			t.Errorf("Delay of attempt %d out of range. Expected between %v and %v, got: %v", attempt+1, max/2, max, delay)
+		}
+	}
+
+	delay := s.retryDelay(1, TrackError{ErrorType: RateLimitError, RetryAfter: 3 * time.Second})
+
+	if delay != 3*time.Second {
+		t.Errorf("Expected delay to be Retry-After. Got: %v", delay)
+	}
+
+	if delay := s.retryDelay(40, TrackError{ErrorType: RateLimitError}); delay > maxRetryDelay || delay < maxRetryDelay/2 {
+		t.Errorf("Expected delay to be between %v and %v. Got: %v", maxRetryDelay/2, maxRetryDelay, delay)
+	}
+
+	if delay := s.retryDelay(100, TrackError{ErrorType: RateLimitError}); delay > maxRetryDelay || delay < maxRetryDelay/2 {
+		t.Errorf("Expected delay of an attempt beyond the bit width to be between %v and %v. Got: %v", maxRetryDelay/2, maxRetryDelay, delay)
+	}
+}
+
+func TestRetryDelayWithoutBaseDelay(t *testing.T) {
+	for _, baseDelay := range []time.Duration{0, -time.Second} {
+		s := NewSearcher(WithRetry(5, baseDelay))
+
+		for attempt := 1; attempt <= 5; attempt++ {
+			if delay := s.retryDelay(attempt, TrackError{ErrorType: ExternalServiceError, StatusCode: 500}); delay != 0 {
+				t.Errorf("Expected no delay with base delay %v. Got: %v", baseDelay, delay)
+			}
+		}
+
+		if delay := s.retryDelay(1, TrackError{ErrorType: RateLimitError, RetryAfter: time.Second}); delay != time.Second {
+			t.Errorf("Expected delay to be Retry-After. Got: %v", delay)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	cases := map[string]time.Duration{
+		"":                              0,
+		"12":                            12 * time.Second,
+		"-3":                            0,
+		"soon":                          0,
+		"Wed, 21 Oct 2015 07:28:30 GMT": 30 * time.Second,
+		"Wed, 21 Oct 2015 07:27:00 GMT": 0,
+	}
+
+	for header, expected := range cases {
+		if actual := parseRetryAfter(header, now); expected != actual {
+			t.Errorf("Unexpected duration of %q. Expected: %v, got: %v", header, expected, actual)
+		}
+	}
+}
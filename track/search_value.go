@@ -0,0 +1,26 @@
+package track
+
+import (
+	"strings"
+)
+
+// searchValueSeparators replaces the quotes and backslashes in the values of a
+// search query with spaces. Spotify documents no way of escaping a quote inside
+// a quoted phrase, so a quote in a value would end the phrase early, and a
+// backslash might be read as escaping the quote closing it.
+var searchValueSeparators = strings.NewReplacer(`"`, " ", `\`, " ")
+
+// searchValue returns value as it is put in a search query, with quotes and
+// backslashes replaced by spaces and whitespace collapsed. The words of value
+// are kept, so the search still finds what value names.
+func searchValue(value string) string {
+	return CollapseWhitespace(searchValueSeparators.Replace(value))
+}
+
+// quoteSearchValue returns value, passed through searchValue, as a quoted
+// phrase for a search query, so that it is searched for as it is. Within the
+// quotes, words such as NOT and OR and field filters such as artist: are taken
+// literally.
+func quoteSearchValue(value string) string {
+	return `"` + searchValue(value) + `"`
+}
@@ -0,0 +1,137 @@
+package track
+
+import (
+	"math/rand"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode"
+)
+
+// searchWords returns the words of value, split at whitespace, quotes and
+// backslashes.
+func searchWords(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '\\'
+	})
+}
+
+// searchValueAlphabet is what generateSearchValue builds values from, with
+// an emphasis on the characters and words that mean something in a query.
+var searchValueAlphabet = []string{`"`, `\`, `\"`, ":", " ", "artist:", "track:", "NOT ", "OR ", "AND ", "-", "*", "a", "Z", "ö", "'", "(", "%", "+", "&"}
+
+func generateSearchValue(r *rand.Rand) string {
+	var b strings.Builder
+
+	for i, n := 0, r.Intn(12); i < n; i++ {
+		if r.Intn(4) == 0 {
+			b.WriteRune(rune(r.Intn(0x3000)))
+		} else {
+			b.WriteString(searchValueAlphabet[r.Intn(len(searchValueAlphabet))])
+		}
+	}
+
+	return b.String()
+}
+
+var searchValueConfig = &quick.Config{
+	MaxCount: 2000,
+	Values: func(values []reflect.Value, r *rand.Rand) {
+		for i := range values {
+			values[i] = reflect.ValueOf(generateSearchValue(r))
+		}
+	},
+}
+
+func TestQuoteSearchValueKeepsWords(t *testing.T) {
+	keepsWords := func(value string) bool {
+		quoted := quoteSearchValue(value)
+
+		if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+			return false
+		}
+
+		phrase := quoted[1 : len(quoted)-1]
+
+		if strings.ContainsAny(phrase, `"\`) {
+			return false
+		}
+
+		return slices.Equal(strings.Fields(phrase), searchWords(value))
+	}
+
+	if err := quick.Check(keepsWords, searchValueConfig); err != nil {
+		t.Error(err)
+	}
+
+	if err := quick.Check(keepsWords, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConstructSearchQueryKeepsFieldsApart(t *testing.T) {
+	keepsFieldsApart := func(title, artist, album string) bool {
+		title = "t" + title
+		artist = "a" + artist
+		album = "b" + album
+
+		searchQueries, _, err := constructSearchQuery(title, artist, album)
+
+		if err != nil || len(searchQueries) != 3 {
+			return false
+		}
+
+		expected := [][]string{
+			{"track:", title, " artist:", artist, " album:", album},
+			{"track:", title, " artist:", artist},
+			{"track:", title, " album:", album},
+		}
+
+		for i, searchQuery := range searchQueries {
+			query, err := url.QueryUnescape(searchQuery)
+
+			if err != nil {
+				return false
+			}
+
+			// Every quote opens or closes a phrase, so the query splits into
+			// the field names and the values between them.
+			parts := strings.Split(query, `"`)
+
+			if len(parts) != len(expected[i])+1 || parts[len(parts)-1] != "" {
+				return false
+			}
+
+			for j := 0; j < len(expected[i]); j += 2 {
+				if parts[j] != expected[i][j] || !slices.Equal(strings.Fields(parts[j+1]), searchWords(expected[i][j+1])) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(keepsFieldsApart, searchValueConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQuoteSearchValue(t *testing.T) {
+	cases := map[string]string{
+		`Human Behaviour`:         `"Human Behaviour"`,
+		`12" Mix`:                 `"12 Mix"`,
+		`artist:Someone NOT This`: `"artist:Someone NOT This"`,
+		`back\slash`:              `"back slash"`,
+		`\" OR track:"x`:          `"OR track: x"`,
+	}
+
+	for value, expected := range cases {
+		if actual := quoteSearchValue(value); expected != actual {
+			t.Errorf("Unexpected result of quoteSearchValue(%q).\nExpected: %s\nActual:   %s", value, expected, actual)
+		}
+	}
+}
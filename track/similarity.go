@@ -0,0 +1,249 @@
+package track
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// MatchWeights are the weights of the parts of the confidence of a match.
+// The similarities of the title, the artists, the album and the duration are
+// averaged into the confidence by the weights Title, Artist, Album and
+// Duration, leaving out the parts that are not searched for, such as the album
+// when none is given. VersionTag is then subtracted from the confidence when
+// the title of the track has other version tags than the title searched for,
+// such as "(Live)" when no version was asked for. When none of the parts
+// searched for has a positive weight, the confidence is zero.
+type MatchWeights struct {
+	Title      float64
+	Artist     float64
+	Album      float64
+	Duration   float64
+	VersionTag float64
+}
+
+// DefaultMatchWeights are the weights used by a Searcher unless others are
+// set with WithMatchWeights.
+var DefaultMatchWeights = MatchWeights{
+	Title:      0.5,
+	Artist:     0.3,
+	Album:      0.2,
+	Duration:   0.2,
+	VersionTag: 0.2,
+}
+
+// maxDurationDelta is how far beyond the tolerated difference the durations
+// of two tracks may differ before they are considered not to be similar at all.
+const maxDurationDelta = 30 * time.Second
+
+// Match tells how well a track matches what was searched for.
+//
+// Confidence is between 0 and 1, where 1 is an exact match, and is made up
+// of the other fields as described by MatchWeights. TitleSimilarity and
+// AlbumSimilarity are between 0 and 1, and AlbumSimilarity is zero when no
+// album was searched for. ArtistOverlap is the average similarity of the
+// artists searched for, including featured ones, to the closest artist of the
+// track. DurationDelta is how far the duration of the track is from the one
+// expected, and zero when no duration is expected. VersionTagPenalty is what
+// was subtracted for differing version tags.
+type Match struct {
+	Confidence        float64
+	TitleSimilarity   float64
+	ArtistOverlap     float64
+	AlbumSimilarity   float64
+	DurationDelta     time.Duration
+	VersionTagPenalty float64
+}
+
+// matchTarget is what a track is searched for by. A track lasting within
+// tolerance of a non-zero duration is considered to have the same duration.
+type matchTarget struct {
+	title     string
+	artist    string
+	album     string
+	duration  time.Duration
+	tolerance time.Duration
+}
+
+// matcher scores tracks against a matchTarget, comparing names normalized by
+// normalizer, if it is not nil. Zero weights mean DefaultMatchWeights.
+type matcher struct {
+	normalizer *Normalizer
+	weights    MatchWeights
+}
+
+// matcher returns the matcher of the Searcher.
+func (s Searcher) matcher() matcher {
+	return matcher{normalizer: s.normalizer, weights: s.matchWeights}
+}
+
+// closestMatch returns the item that scores highest against target, converted
+// to a Track with its Match set. Items scoring equally are ranked in the order
+// the API returned them.
+func (m matcher) closestMatch(items []item, target matchTarget) Track {
+	var best Track
+
+	for i, candidate := range items {
+		track := trackFromItem(candidate)
+		track.Match = m.score(track, target)
+
+		if i == 0 || track.Match.Confidence > best.Match.Confidence {
+			best = track
+		}
+	}
+
+	return best
+}
+
+// score returns how well candidate matches target.
+func (m matcher) score(candidate Track, target matchTarget) Match {
+	w := m.weights
+
+	if w == (MatchWeights{}) {
+		w = DefaultMatchWeights
+	}
+
+	n := m.normalizer
+
+	title, titleTags := SplitVersionTags(target.title)
+	candidateTitle, candidateTags := SplitVersionTags(candidate.Name)
+
+	match := Match{TitleSimilarity: similarity(n.Normalize(title), n.Normalize(candidateTitle))}
+
+	score := w.Title * match.TitleSimilarity
+	total := w.Title
+
+	if strings.TrimSpace(target.artist) != "" {
+		match.ArtistOverlap = m.artistOverlap(candidate.Artists, target.artist)
+		score += w.Artist * match.ArtistOverlap
+		total += w.Artist
+	}
+
+	if strings.TrimSpace(target.album) != "" {
+		match.AlbumSimilarity = similarity(n.Normalize(target.album), n.Normalize(candidate.Album.Name))
+		score += w.Album * match.AlbumSimilarity
+		total += w.Album
+	}
+
+	if target.duration > 0 {
+		match.DurationDelta = (candidate.Duration - target.duration).Abs()
+		excess := max(0, match.DurationDelta-target.tolerance)
+		score += w.Duration * max(0, 1-float64(excess)/float64(maxDurationDelta))
+		total += w.Duration
+	}
+
+	if !sameVersionTags(titleTags, candidateTags) {
+		match.VersionTagPenalty = w.VersionTag
+	}
+
+	// Weights leaving out every part searched for give no confidence at all,
+	// rather than a perfect match for any track.
+	if total > 0 {
+		match.Confidence = max(0, min(1, score/total-match.VersionTagPenalty))
+	}
+
+	return match
+}
+
+// artistOverlap returns the average similarity of the artists in artist,
+// the main one and those credited as featured, to their closest artist
+// among artists.
+func (m matcher) artistOverlap(artists []Artist, artist string) float64 {
+	main, featured := SplitFeaturedArtists(artist)
+	searched := append([]string{main}, featured...)
+
+	sum := 0.0
+
+	for _, name := range searched {
+		best := 0.0
+
+		for _, a := range artists {
+			if sim := similarity(m.normalizer.Normalize(name), m.normalizer.Normalize(a.Name)); sim > best {
+				best = sim
+			}
+		}
+
+		sum += best
+	}
+
+	return sum / float64(len(searched))
+}
+
+// sameVersionTags tells whether a and b hold the same version tags, ignoring
+// case, whitespace and order.
+func sameVersionTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	fold := func(tags []string) []string {
+		folded := make([]string, len(tags))
+
+		for i, tag := range tags {
+			folded[i] = strings.ToLower(CollapseWhitespace(tag))
+		}
+
+		sort.Strings(folded)
+
+		return folded
+	}
+
+	fa, fb := fold(a), fold(b)
+
+	for i := range fa {
+		if fa[i] != fb[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// similarity returns a value between 0 and 1 telling how alike a and b are,
+// based on the Levenshtein distance between them. Case and surrounding
+// whitespace are ignored.
+func similarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSpace(a)))
+	rb := []rune(strings.ToLower(strings.TrimSpace(b)))
+
+	longest := len(ra)
+
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
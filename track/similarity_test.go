@@ -0,0 +1,201 @@
+package track
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joarleth/spotify/track/tracktest"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"Björk", "Bjork", 1},
+	}
+
+	for _, c := range cases {
+		actual := levenshtein([]rune(c.a), []rune(c.b))
+
+		if c.expected != actual {
+			t.Errorf("Unexpected distance between %q and %q. Expected: %d, got: %d", c.a, c.b, c.expected, actual)
+		}
+	}
+}
+
+func TestSimilarityIgnoresCaseAndWhitespace(t *testing.T) {
+	actual := similarity(" Human Behaviour", "human behaviour ")
+
+	if actual != 1 {
+		t.Errorf("Expected similarity to be 1. Got: %v", actual)
+	}
+}
+
+func TestScoreLeavesOutEmptyAlbum(t *testing.T) {
+	candidate := Track{Name: "Human Behaviour", Artists: []Artist{Artist{Name: "Björk"}}, Album: Album{Name: "Debut"}}
+
+	actual := matcher{}.score(candidate, matchTarget{title: "Human Behaviour", artist: "Björk"}).Confidence
+
+	if actual != 1 {
+		t.Errorf("Expected score to be 1. Got: %v", actual)
+	}
+}
+
+func TestScoreUsesBestMatchingArtist(t *testing.T) {
+	candidate := Track{Name: "Labyrinth", Artists: []Artist{Artist{Name: "Someone Else"}, Artist{Name: "Bella Hardy"}}}
+
+	actual := matcher{}.score(candidate, matchTarget{title: "Labyrinth", artist: "Bella Hardy"}).Confidence
+
+	if actual != 1 {
+		t.Errorf("Expected score to be 1. Got: %v", actual)
+	}
+}
+
+func TestScorePenalizesOtherVersions(t *testing.T) {
+	target := matchTarget{title: "Human Behaviour", artist: "Björk"}
+	live := Track{Name: "Human Behaviour - Live", Artists: []Artist{{Name: "Björk"}}}
+
+	match := matcher{}.score(live, target)
+
+	expected := Match{Confidence: 0.8, TitleSimilarity: 1, ArtistOverlap: 1, VersionTagPenalty: 0.2}
+
+	if match != expected {
+		t.Errorf("Unexpected match.\nExpected: %+v\nActual:   %+v", expected, match)
+	}
+
+	target.title = "Human Behaviour (live)"
+
+	if match := (matcher{}).score(live, target); match.Confidence != 1 {
+		t.Errorf("Expected the same version to match exactly. Got: %+v", match)
+	}
+}
+
+func TestScoreArtistOverlapCountsFeaturedArtists(t *testing.T) {
+	candidate := Track{Name: "Song", Artists: []Artist{{Name: "Main"}, {Name: "Guest"}}}
+
+	match := matcher{}.score(candidate, matchTarget{title: "Song", artist: "Main feat. Guest & Xyz"})
+
+	if expected := 2.0 / 3; match.ArtistOverlap < expected-1e-9 || match.ArtistOverlap > expected+1e-9 {
+		t.Errorf("Unexpected artist overlap. Expected: %v, got: %v", expected, match.ArtistOverlap)
+	}
+}
+
+func TestScoreDuration(t *testing.T) {
+	candidate := Track{Name: "Song", Artists: []Artist{{Name: "Artist"}}, Duration: 200 * time.Second}
+	target := matchTarget{title: "Song", artist: "Artist", duration: 215 * time.Second}
+
+	match := matcher{}.score(candidate, target)
+
+	if match.DurationDelta != 15*time.Second {
+		t.Errorf("Unexpected duration delta. Expected: %v, got: %v", 15*time.Second, match.DurationDelta)
+	}
+
+	// Title and artist are exact, and the duration is half way to maxDurationDelta.
+	if expected := (0.5 + 0.3 + 0.2*0.5) / 1.0; match.Confidence != expected {
+		t.Errorf("Unexpected confidence. Expected: %v, got: %v", expected, match.Confidence)
+	}
+}
+
+func TestScoreWithWeights(t *testing.T) {
+	candidate := Track{Name: "Song", Artists: []Artist{{Name: "Someone Else"}}}
+	target := matchTarget{title: "Song", artist: "Artist"}
+
+	titleOnly := matcher{weights: MatchWeights{Title: 1}}.score(candidate, target)
+
+	if titleOnly.Confidence != 1 {
+		t.Errorf("Expected artist to be left out with a zero weight. Got: %v", titleOnly.Confidence)
+	}
+
+	if byDefault := (matcher{}).score(candidate, target); byDefault.Confidence >= 1 {
+		t.Errorf("Expected the artist to lower the confidence. Got: %v", byDefault.Confidence)
+	}
+}
+
+func TestScoreWithoutWeightedParts(t *testing.T) {
+	candidate := Track{Name: "Other", Album: Album{Name: "Other"}}
+	target := matchTarget{title: "Song", album: "Album"}
+
+	if actual := (matcher{weights: MatchWeights{Artist: 1}}).score(candidate, target); actual.Confidence != 0 {
+		t.Errorf("Expected no confidence when no part searched for is weighted. Got: %v", actual.Confidence)
+	}
+}
+
+func TestWithMatchWeightsValidatesWeights(t *testing.T) {
+	s := NewSearcher(WithMatchWeights(MatchWeights{Title: 1, Artist: -1, VersionTag: -0.5}))
+
+	if expected := (MatchWeights{Title: 1}); s.matchWeights != expected {
+		t.Errorf("Expected negative weights to be zero.\nExpected: %+v\nActual:   %+v", expected, s.matchWeights)
+	}
+
+	for _, weights := range []MatchWeights{{}, {VersionTag: 1}, {Title: -1, Artist: -1}} {
+		if s := NewSearcher(WithMatchWeights(weights)); s.matchWeights != (MatchWeights{}) {
+			t.Errorf("Expected weights %+v to be ignored. Got: %+v", weights, s.matchWeights)
+		}
+	}
+}
+
+func TestFindSetsMatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tracks": {"items": [{"uri": "spotify:track:4ry6oqlwdsooYtniYJFkt5", "name": "Human Behaviour - 2002 Remaster", "artists": [{"name": "Björk"}]}]}}`))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL, WithMatchWeights(MatchWeights{Title: 1, Artist: 1, VersionTag: 0.5}))
+
+	track, err := s.Find("Human Behaviour", "Björk", "")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	expected := Match{Confidence: 0.5, TitleSimilarity: 1, ArtistOverlap: 1, VersionTagPenalty: 0.5}
+
+	if track.Match != expected {
+		t.Errorf("Unexpected match.\nExpected: %+v\nActual:   %+v", expected, track.Match)
+	}
+}
+
+func TestFindAllSetsMatch(t *testing.T) {
+	bjork := []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Björk"}}
+
+	server := tracktest.NewServer(
+		tracktest.Track{Id: "0Ypg6RWDG5b4nZ8MyEDbCg", Name: "Army of Me", Artists: bjork, Album: tracktest.Album{Id: "2cWBwpqMsDJC1ZUwz813lo", Name: "Post"}},
+		tracktest.Track{Id: "1hn2U7NwSwVQ9Lt2WF1iK6", Name: "Army of Me", Artists: bjork, Album: tracktest.Album{Id: "4hBA7VgOSxsWOf2N9dJv2X", Name: "Greatest Hits"}},
+		tracktest.Track{Id: "5Ct1CZ0L9YgQUPt9DWGwXv", Name: "Army of Me (Live)", Artists: bjork, Album: tracktest.Album{Id: "7ajJCpBDS27CaA4I4IDzXr", Name: "Live"}},
+	)
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
+
+	result, err := s.FindAll("Army of Me", "Björk", "", FindAllOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if len(result.Tracks) != 3 {
+		t.Fatalf("Expected 3 tracks. Got: %d", len(result.Tracks))
+	}
+
+	for _, track := range result.Tracks[:2] {
+		if track.Match.Confidence != 1 || track.Match.TitleSimilarity != 1 || track.Match.ArtistOverlap != 1 {
+			t.Errorf("Expected an exact match for %s. Got: %+v", track.Album.Name, track.Match)
+		}
+	}
+
+	if live := result.Tracks[2].Match; live.Confidence >= 1 || live.VersionTagPenalty == 0 {
+		t.Errorf("Expected the live version to be penalized. Got: %+v", live)
+	}
+
+	searched, _ := s.Search(Query{}.Track("Army of Me"), FindAllOptions{})
+
+	if len(searched.Tracks) != 3 || searched.Tracks[0].Match != (Match{}) {
+		t.Errorf("Expected the tracks of Search not to be scored. Got: %v", searched.Tracks)
+	}
+}
@@ -3,20 +3,31 @@ package track
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/joarleth/spotify/track/tracktest"
 )
 
 func TestFind(t *testing.T) {
-	s := NewSearcher()
+	bellaHardy := []tracktest.Artist{{Id: "6bp4nIw3LQpmI9xa5kTBAc", Name: "Bella Hardy"}}
+
+	server := tracktest.NewServer(
+		tracktest.Track{Id: "3O0oANShbl9kRHS3ROamDd", Name: "Labyrinth", Artists: []tracktest.Artist{{Id: "2yGM3ENEmwWs1YT0dBuoqm", Name: "Elbow"}}},
+		tracktest.Track{Id: "7f7y9A3Spuus0SBsuDMdMa", Name: "Labyrinth", Artists: bellaHardy, Album: tracktest.Album{Id: "0bLvyr6T1ZdwVgXQnRvCRr", Name: "Songs Lost & Stolen"}},
+		tracktest.Track{Id: "4KfG2TQFMlDzqGfIXk9Zg6", Name: "Labyrinth", Artists: bellaHardy, Album: tracktest.Album{Id: "5Fq3Y8pN3zqyCwC7fG0Hv1", Name: "The Dark Peak And The White"}},
+	)
+	defer server.Close()
+
+	s := NewSearcher(WithBaseURL(server.URL))
 
-	expected := Track{Name: "Labyrinth",
-		Uri:     "spotify:track:7f7y9A3Spuus0SBsuDMdMa",
-		Artists: []string{"Bella Hardy"},
-		Album:   "Songs Lost & Stolen",
-	}
 	actual, _ := s.Find("Labyrinth", "Bella Hardy", "")
 
-	if !reflect.DeepEqual(expected, actual) {
-		t.Errorf("Actual track not matching expected. \nExpected: %v\nActual:   %v\n", expected, actual)
+	if actual.Name != "Labyrinth" ||
+		actual.Uri != "spotify:track:7f7y9A3Spuus0SBsuDMdMa" ||
+		len(actual.Artists) != 1 || actual.Artists[0].Name != "Bella Hardy" ||
+		actual.Album.Name != "Songs Lost & Stolen" ||
+		actual.QueryLevel != TitleArtistQuery {
+		t.Errorf("Actual track not matching expected. \nActual:   %v\n", actual)
 	}
 }
 
@@ -32,10 +43,39 @@ func TestExtractTracksFromJSON(t *testing.T) {
 		t.Errorf("Unexpected number of tracks. Expected: %v, got: %v", expectedLength, actualLength)
 	}
 
-	expectedFirstTrack := item{Uri: "spotify:track:4ry6oqlwdsooYtniYJFkt5",
-		Name:    "Human Behaviour",
-		Artists: []artist{artist{Name: "Björk"}},
-		Album:   album{Name: "Debut (Ecopac)"},
+	markets := []string{"AR", "AT", "AU", "BE", "BG", "BR", "CH", "CL", "CO", "CR", "CY", "CZ", "DK", "DO", "EC", "EE", "FI", "FR", "GR", "HK", "HU", "IE", "IT", "LT", "LU", "LV", "MT", "MY", "NL", "NO", "NZ", "PE", "PH", "PL", "PT", "RO", "SE", "SG", "SI", "SK", "TR", "TW", "UY"}
+
+	expectedFirstTrack := item{
+		Id:   "4ry6oqlwdsooYtniYJFkt5",
+		Uri:  "spotify:track:4ry6oqlwdsooYtniYJFkt5",
+		Name: "Human Behaviour",
+		Artists: []artist{artist{
+			Id:           "7w29UYBi0qsHi5RTcv3lmA",
+			Name:         "Björk",
+			Uri:          "spotify:artist:7w29UYBi0qsHi5RTcv3lmA",
+			ExternalUrls: map[string]string{"spotify": "https://open.spotify.com/artist/7w29UYBi0qsHi5RTcv3lmA"},
+		}},
+		Album: album{
+			Id:        "1Xa4WU2bxfuKCgGDga6NWx",
+			Name:      "Debut (Ecopac)",
+			Uri:       "spotify:album:1Xa4WU2bxfuKCgGDga6NWx",
+			AlbumType: "album",
+			Images: []image{
+				image{Url: "https://i.scdn.co/image/8b424083cdd36c37c6b1a50a71870dc3f68ba366", Width: 640, Height: 634},
+				image{Url: "https://i.scdn.co/image/04fad8987bc691fab5f1354541d7214681af8478", Width: 300, Height: 297},
+				image{Url: "https://i.scdn.co/image/49925a811edf4925305d3abccbad42365603ab9e", Width: 64, Height: 63},
+			},
+			AvailableMarkets: markets,
+			ExternalUrls:     map[string]string{"spotify": "https://open.spotify.com/album/1Xa4WU2bxfuKCgGDga6NWx"},
+		},
+		AvailableMarkets: markets,
+		DurationMs:       250933,
+		Popularity:       46,
+		DiscNumber:       1,
+		TrackNumber:      1,
+		ExternalIds:      externalIds{Isrc: "GBBTF9300001"},
+		PreviewUrl:       "https://p.scdn.co/mp3-preview/3b9f16caa6cf1d354941fa6f13ca6c87cb0f60f3",
+		ExternalUrls:     map[string]string{"spotify": "https://open.spotify.com/track/4ry6oqlwdsooYtniYJFkt5"},
 	}
 
 	actualFirstTrack := trackCollection.Tracks.Items[0]
@@ -45,6 +85,59 @@ func TestExtractTracksFromJSON(t *testing.T) {
 	}
 }
 
+func TestTrackFromItem(t *testing.T) {
+	data := getTextFileData(t, "test_data/tracks.json")
+
+	trackCollection, _ := extractTrackCollectionFromJSON(data)
+
+	actual := trackFromItem(trackCollection.Tracks.Items[0])
+
+	expectedArtist := Artist{
+		Id:           "7w29UYBi0qsHi5RTcv3lmA",
+		Name:         "Björk",
+		Uri:          "spotify:artist:7w29UYBi0qsHi5RTcv3lmA",
+		ExternalUrls: map[string]string{"spotify": "https://open.spotify.com/artist/7w29UYBi0qsHi5RTcv3lmA"},
+	}
+
+	if len(actual.Artists) != 1 || !reflect.DeepEqual(expectedArtist, actual.Artists[0]) {
+		t.Errorf("Unexpected artists.\nExpected: %v\nActual:   %v", expectedArtist, actual.Artists)
+	}
+
+	if actual.Album.Id != "1Xa4WU2bxfuKCgGDga6NWx" || actual.Album.Uri != "spotify:album:1Xa4WU2bxfuKCgGDga6NWx" || len(actual.Album.Images) != 3 {
+		t.Errorf("Unexpected album: %v", actual.Album)
+	}
+
+	expectedImage := Image{Url: "https://i.scdn.co/image/49925a811edf4925305d3abccbad42365603ab9e", Width: 64, Height: 63}
+
+	if len(actual.Album.Images) == 3 && !reflect.DeepEqual(expectedImage, actual.Album.Images[2]) {
+		t.Errorf("Unexpected album image.\nExpected: %v\nActual:   %v", expectedImage, actual.Album.Images[2])
+	}
+
+	if actual.Id != "4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Unexpected id: %s", actual.Id)
+	}
+
+	if actual.Duration != 250933*time.Millisecond {
+		t.Errorf("Unexpected duration: %v", actual.Duration)
+	}
+
+	if actual.Isrc != "GBBTF9300001" {
+		t.Errorf("Unexpected isrc: %s", actual.Isrc)
+	}
+
+	if actual.Popularity != 46 || actual.DiscNumber != 1 || actual.TrackNumber != 1 || actual.Explicit {
+		t.Errorf("Unexpected popularity, disc number, track number or explicit flag: %v", actual)
+	}
+
+	if actual.PreviewUrl != "https://p.scdn.co/mp3-preview/3b9f16caa6cf1d354941fa6f13ca6c87cb0f60f3" {
+		t.Errorf("Unexpected preview url: %s", actual.PreviewUrl)
+	}
+
+	if actual.ExternalUrls["spotify"] != "https://open.spotify.com/track/4ry6oqlwdsooYtniYJFkt5" {
+		t.Errorf("Unexpected external urls: %v", actual.ExternalUrls)
+	}
+}
+
 /*
 func TestExtractTracksFromJSONFirstTrackCorrect(t *testing.T) {
 	xml_data := getTextFileData(t, "tracks.xml")
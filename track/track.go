@@ -2,15 +2,42 @@
 package track
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 )
 
-const trackSearchBaseUrl = "https://api.spotify.com/v1/search/?type=track&q="
+const defaultBaseUrl = "https://api.spotify.com/v1"
+
+// trackSearchPath is appended to the base url of the API to get the
+// url searching for tracks, to which the search query is appended.
+const trackSearchPath = "/search/?type=track&q="
+
+// maxSearchLimit is the largest number of tracks the search endpoint
+// returns in a single page.
+const maxSearchLimit = 50
+
+// closestMatchLimit is the number of search results FindClosestMatch
+// picks the best match from, unless the Searcher has a limit of its own.
+const closestMatchLimit = maxSearchLimit
+
+// defaultFindAllLimit is the number of tracks returned by FindAll when
+// neither the options nor the Searcher has a limit. It is the same as
+// the default of the API.
+const defaultFindAllLimit = 20
+
+// marketFindLimit is the number of search results Find looks through for a
+// track playable in the market of the Searcher.
+const marketFindLimit = 20
 
 type ErrorType int
 
@@ -19,29 +46,146 @@ const (
 	UnexpectedError
 	ExternalServiceError
 	RateLimitError
+	CanceledError
+	AuthenticationError
+	NotFoundError
 )
 
+// QueryLevel tells which of the search queries tried by Find a track was
+// found by. The zero value means that no query matched.
+type QueryLevel int
+
+const (
+	NoQueryLevel QueryLevel = iota
+	TitleArtistAlbumQuery
+	TitleArtistQuery
+	TitleAlbumQuery
+	IsrcQuery
+)
+
+func (ql QueryLevel) String() string {
+	switch ql {
+	case TitleArtistAlbumQuery:
+		return "title+artist+album"
+	case TitleArtistQuery:
+		return "title+artist"
+	case TitleAlbumQuery:
+		return "title+album"
+	case IsrcQuery:
+		return "isrc"
+	}
+
+	return "none"
+}
+
 // Track represent a Spotify track
 type Track struct {
-	Name    string
-	Artists []string
-	Album   string
-	Uri     string
+	Id               string
+	Name             string
+	Artists          []Artist
+	Album            Album
+	Uri              string
+	Duration         time.Duration
+	Explicit         bool
+	Popularity       int
+	DiscNumber       int
+	TrackNumber      int
+	Isrc             string
+	PreviewUrl       string
+	ExternalUrls     map[string]string
+	AvailableMarkets []string
+	QueryLevel       QueryLevel
+	Match            Match
+}
+
+// Artist represent an artist of a Spotify track
+type Artist struct {
+	Id           string
+	Name         string
+	Uri          string
+	ExternalUrls map[string]string
 }
 
+// Album represent the album a Spotify track appears on
+type Album struct {
+	Id           string
+	Name         string
+	Uri          string
+	AlbumType    string
+	Images       []Image
+	ExternalUrls map[string]string
+}
+
+// Image is an album cover in one of the sizes provided by Spotify
+type Image struct {
+	Url    string
+	Width  int
+	Height int
+}
+
+// Searcher searches for tracks playable in its market. An empty market
+// means that tracks are not filtered on availability.
 type Searcher struct {
+	market             string
+	baseUrl            string
 	trackSearchBaseUrl string
+	limit              int
+	userAgent          string
+	client             *http.Client
+	tokenUrl           string
+	tokens             *tokenSource
+	maxAttempts        int
+	retryBaseDelay     time.Duration
+	limiter            *RateLimiter
+	logHandler         slog.Handler
+	normalizer         *Normalizer
+	matchWeights       MatchWeights
+	cache              Cache
+	matchCache         Cache
+	cacheTTL           time.Duration
+	negativeCacheTTL   time.Duration
 }
 
+// Sentinel errors that a TrackError matches with errors.Is, depending on its
+// ErrorType and StatusCode.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// TrackError is the error returned by the functions of the package.
+// StatusCode is the HTTP status of the failed response from Spotify, if any,
+// and RetryAfter is how long Spotify asked to wait before the next request
+// when the ErrorType is RateLimitError. Url is the url of the failed request,
+// and SpotifyError the error object in the body of the response, if there was one.
+// Queries are the search queries tried when the ErrorType is NotFoundError.
 type TrackError struct {
 	Msg           string
 	ErrorType     ErrorType
 	OriginalError error
+	StatusCode    int
+	RetryAfter    time.Duration
+	Url           string
+	SpotifyError  *SpotifyError
+	Queries       []string
+}
+
+// SpotifyError is the error object the Spotify Web API responds with when a
+// request fails. (https://developer.spotify.com/documentation/web-api/concepts/api-calls)
+type SpotifyError struct {
+	Status  int
+	Message string
 }
 
 func (te TrackError) Error() string {
 	msg := "github.com/joarleth/spotify/track: " + te.Msg
 
+	if te.SpotifyError != nil && te.SpotifyError.Message != "" {
+		msg += " Spotify error: " + te.SpotifyError.Message
+	}
+
 	if te.OriginalError != nil {
 		msg += " Original error: " + te.OriginalError.Error()
 	}
@@ -49,195 +193,925 @@ func (te TrackError) Error() string {
 	return msg
 }
 
-// NewSearcher initializes a default searcher object
-func NewSearcher() *Searcher {
-	return &Searcher{
-		trackSearchBaseUrl: trackSearchBaseUrl,
+// Unwrap returns the error that caused te, if any.
+func (te TrackError) Unwrap() error {
+	return te.OriginalError
+}
+
+// Is reports whether te matches one of the sentinel errors of the package.
+func (te TrackError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return te.ErrorType == NotFoundError || te.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return te.ErrorType == RateLimitError
+	case ErrUnauthorized:
+		return te.ErrorType == AuthenticationError
+	case ErrInvalidArgument:
+		return te.ErrorType == ArgumentError
+	}
+
+	return false
+}
+
+// NewSearcher initializes a searcher object configured by opts. Without any options
+// it searches all markets of the Spotify Web API using http.DefaultClient, without
+// authorizing its requests.
+func NewSearcher(opts ...Option) *Searcher {
+	s := &Searcher{
+		baseUrl:            defaultBaseUrl,
+		trackSearchBaseUrl: defaultBaseUrl + trackSearchPath,
+		client:             http.DefaultClient,
+		tokenUrl:           defaultTokenUrl,
+		maxAttempts:        1,
+		cacheTTL:           defaultCacheTTL,
+		negativeCacheTTL:   defaultNegativeCacheTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.tokens != nil {
+		s.tokens.tokenUrl = s.tokenUrl
 	}
+
+	return s
 }
 
 // Find returns a track from Spotify matching title and at least one of artist and album.
 // The data is fetched from Spotify's Web API. (https://developer.spotify.com/web-api/)
-func (s Searcher) Find(title, artist, album string) (Track, error) {
-	searchQueries, err := constructSearchQuery(title, artist, album)
+//
+// When both artist and album are given and no track matches all three, Find falls back
+// to searching on title and artist, and then on title and album. The QueryLevel of the
+// returned track tells which of the queries it was found by, and its Match tells how
+// well it matches what was searched for.
+// Tracks not playable in the market of the Searcher are skipped, and PreferDuration and
+// RequireDuration pick among the results by their duration.
+// If none of the queries finds a track, a TrackError with ErrorType NotFoundError,
+// matching ErrNotFound and listing the queries tried, is returned.
+func (s Searcher) Find(title, artist, album string, opts ...FindOption) (Track, error) {
+	return s.FindContext(context.Background(), title, artist, album, opts...)
+}
+
+// FindContext is like Find, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before the search is done, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) FindContext(ctx context.Context, title, artist, album string, opts ...FindOption) (Track, error) {
+	searchQueries, queryLevels, err := constructSearchQuery(s.normalizeQuery(title, artist, album))
 
 	if err != nil {
 		return Track{}, err
 	}
 
-	// TODO: Loop through search queries if more than one and no track found
-	url := s.trackSearchBaseUrl + searchQueries[0] + "&limit=1"
+	fo := newFindOptions(opts)
+	matchKey := s.matchKey(title, artist, album, fo)
 
-	println(url)
+	if ctxErr := contextError(ctx); ctxErr != nil {
+		return Track{}, ctxErr
+	}
 
-	data, fetchError := fetchData(url)
+	if track, found := s.cachedMatch(matchKey); found {
+		s.logger().InfoContext(ctx, "track found in match cache", "uri", track.Uri, "query_level", track.QueryLevel.String())
 
-	if fetchError != nil {
-		return Track{}, fetchError
+		return track, nil
 	}
 
-	track, extractError := s.extractTrackFromJSON(data)
+	limit := 1
 
-	if extractError != nil {
-		return Track{}, extractError
+	if s.market != "" || fo.duration > 0 {
+		limit = marketFindLimit
 	}
 
-	return track, nil
-}
+	for i, searchQuery := range searchQueries {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return Track{}, ctxErr
+		}
+
+		url := s.searchUrl(searchQuery, limit)
+
+		s.logSearchStep(ctx, "Find", i, queryLevels[i], searchQuery)
+
+		data, fetchError := s.fetchData(ctx, url)
+
+		if fetchError != nil {
+			return Track{}, fetchError
+		}
+
+		track, extractError := s.extractTrackFromJSON(data, fo)
+
+		if extractError != nil {
+			return Track{}, extractError
+		}
+
+		if track.Uri != "" {
+			track.QueryLevel = queryLevels[i]
+			track.Match = s.matcher().score(track, matchTarget{title: title, artist: artist, album: album, duration: fo.duration, tolerance: fo.tolerance})
+			s.logger().InfoContext(ctx, "track found", "uri", track.Uri, "query_level", track.QueryLevel.String(), "confidence", track.Match.Confidence)
+			s.cacheMatch(matchKey, track)
+
+			return track, nil
+		}
+	}
 
-/*
+	s.logger().InfoContext(ctx, "no track found", "queries", len(searchQueries))
 
-// GetClosestMatch returns a track from spotify matching title and at least one of artist and album.
-// The data is fetched from Spotify's metadata API. (https://developer.spotify.com/technologies/web-api/)
-// The first track containing the territory string of the Searcher instance, or "worldwide" in it's
-// territories string
-// is returned.
+	return Track{}, notFoundError(searchQueries)
+}
+
+// FindClosestMatch returns the track from Spotify that best matches title and at least one
+// of artist and album, together with the confidence of its Match.
+// The data is fetched from Spotify's Web API. (https://developer.spotify.com/web-api/)
+// Rather than trusting the order of the search results, a page of up to closestMatchLimit
+// tracks is fetched and every track is scored by how similar its name, artists and album
+// are to the ones asked for, and to the duration given with PreferDuration or
+// RequireDuration, as described by MatchWeights. The confidence is between 0
+// and 1, where 1 is an exact match.
+// The same fallback queries as in Find are tried until one of them returns any tracks
+// playable in the market of the Searcher. If none of them does, a TrackError with
+// ErrorType NotFoundError is returned.
 //
-// Please beware of rate limits;
+// Please beware of rate limits, which WithRateLimiter helps staying under;
 // "The rate limit is currently 10 request per second per ip. This may change."
-func (s Searcher) FindClosestMatch(title, artist, album string) (Track, error) {
-	search_queries, err := constructSearchQuery(title, artist, album)
+func (s Searcher) FindClosestMatch(title, artist, album string, opts ...FindOption) (Track, float64, error) {
+	return s.FindClosestMatchContext(context.Background(), title, artist, album, opts...)
+}
+
+// FindClosestMatchContext is like FindClosestMatch, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before the search is done, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) FindClosestMatchContext(ctx context.Context, title, artist, album string, opts ...FindOption) (Track, float64, error) {
+	searchQueries, queryLevels, err := constructSearchQuery(s.normalizeQuery(title, artist, album))
 
 	if err != nil {
-		return Track{}, err
+		return Track{}, 0, err
 	}
 
-	// TODO: Loop through search queries if more than one and no track found
-	url := s.trackSearchBaseUrl + "/" + search_queries[0]
+	fo := newFindOptions(opts)
+
+	for i, searchQuery := range searchQueries {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return Track{}, 0, ctxErr
+		}
+
+		url := s.searchUrl(searchQuery, s.limitOr(closestMatchLimit))
+
+		s.logSearchStep(ctx, "FindClosestMatch", i, queryLevels[i], searchQuery)
 
-	xml_data, fetch_error := fetchTracksXML(url)
+		data, fetchError := s.fetchData(ctx, url)
+
+		if fetchError != nil {
+			return Track{}, 0, fetchError
+		}
+
+		trackCollection, extractError := extractTrackCollectionFromJSON(data)
+
+		if extractError != nil {
+			return Track{}, 0, extractError
+		}
 
-	if fetch_error != nil {
-		return Track{}, fetch_error
+		items := fo.filter(s.playableItems(trackCollection.Tracks.Items))
+
+		if len(items) > 0 {
+			track := s.matcher().closestMatch(items, matchTarget{title: title, artist: artist, album: album, duration: fo.duration, tolerance: fo.tolerance})
+			track.QueryLevel = queryLevels[i]
+
+			s.logger().InfoContext(ctx, "closest match found", "uri", track.Uri, "query_level", track.QueryLevel.String(), "confidence", track.Match.Confidence, "candidates", len(items))
+
+			return track, track.Match.Confidence, nil
+		}
 	}
 
-	track, extract_err := s.extractSingleTrackFromXML(xml_data)
+	s.logger().InfoContext(ctx, "no track found", "queries", len(searchQueries))
+
+	return Track{}, 0, notFoundError(searchQueries)
+}
+
+// FindAllOptions controls which of the matching tracks FindAll returns.
+// Offset is the index of the first track to return and Limit the largest
+// number of tracks to return. A zero Limit means the limit of the Searcher,
+// or defaultFindAllLimit if it has none.
+//
+// Offset counts the tracks as the API returns them, including the ones not
+// playable in the market of the Searcher, so the Offset of a following page
+// should be the NextOffset of the FindAllResult of the previous one rather than
+// be computed from the number of tracks. QueryLevel is the query searched when
+// Offset is not zero, and should be the QueryLevel of that FindAllResult too,
+// so that all pages of a list come from the same query. A zero QueryLevel
+// means the first query.
+type FindAllOptions struct {
+	Offset     int
+	Limit      int
+	QueryLevel QueryLevel
+}
 
-	if extract_err != nil {
-		return Track{}, extract_err
+// FindAllResult is a page of the tracks returned by FindAll or Search.
+// Total is the number of tracks matching the search as reported by the API, which
+// includes tracks not playable in the market of the Searcher even though these are
+// left out of Tracks. QueryLevel is the query the tracks were found by. NextOffset
+// is the Offset to pass to get the tracks following Tracks, and zero when there are
+// no more.
+type FindAllResult struct {
+	Tracks     []Track
+	Total      int
+	NextOffset int
+	QueryLevel QueryLevel
+}
+
+// FindAll returns the tracks from Spotify matching title and at least one of artist and album,
+// in the order Spotify ranks them, together with the total number of tracks matching the search.
+// The Match of every track tells how well it matches title, artist and album, as in FindClosestMatch.
+// Limits above the page size of the API are fetched by following the next links of the pages.
+// For the first page, the same fallback queries as in Find are tried until one of them returns
+// any tracks. The following pages are fetched from the query of opts.QueryLevel only.
+func (s Searcher) FindAll(title, artist, album string, opts FindAllOptions) (FindAllResult, error) {
+	return s.FindAllContext(context.Background(), title, artist, album, opts)
+}
+
+// FindAllContext is like FindAll, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before the search is done, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) FindAllContext(ctx context.Context, title, artist, album string, opts FindAllOptions) (FindAllResult, error) {
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return FindAllResult{}, TrackError{Msg: "Offset and limit passed to FindAll must not be negative.", ErrorType: ArgumentError}
+	}
+
+	limit := opts.Limit
+
+	if limit == 0 {
+		limit = s.limitOr(defaultFindAllLimit)
+	}
+
+	searchQueries, queryLevels, err := constructSearchQuery(s.normalizeQuery(title, artist, album))
+
+	if err != nil {
+		return FindAllResult{}, err
+	}
+
+	if opts.Offset > 0 {
+		i := 0
+
+		if opts.QueryLevel != NoQueryLevel {
+			i = slices.Index(queryLevels, opts.QueryLevel)
+		}
+
+		if i < 0 || i >= len(searchQueries) {
+			return FindAllResult{}, TrackError{Msg: "QueryLevel passed to FindAll is not one of the queries searched.", ErrorType: ArgumentError}
+		}
+
+		searchQueries = searchQueries[i : i+1]
+		queryLevels = queryLevels[i : i+1]
+	}
+
+	for i, searchQuery := range searchQueries {
+		url := s.searchUrl(searchQuery, min(limit, maxSearchLimit))
+
+		if opts.Offset > 0 {
+			url += fmt.Sprintf("&offset=%d", opts.Offset)
+		}
+
+		s.logSearchStep(ctx, "FindAll", i, queryLevels[i], searchQuery)
+
+		result, err := s.collectTracks(ctx, url, opts.Offset, limit, queryLevels[i], &matchTarget{title: title, artist: artist, album: album})
+
+		if err != nil {
+			return FindAllResult{}, err
+		}
+
+		if len(result.Tracks) > 0 || opts.Offset > 0 {
+			return result, nil
+		}
+	}
+
+	return FindAllResult{}, nil
+}
+
+// FindByISRC returns all tracks from Spotify with the International Standard Recording Code isrc,
+// such as "GBBTF9300001". The same recording is often released on several albums, so there may be
+// more than one track. Hyphens in isrc are ignored. The tracks are not scored, so their Match
+// is the zero Match.
+func (s Searcher) FindByISRC(isrc string) ([]Track, error) {
+	return s.FindByISRCContext(context.Background(), isrc)
+}
+
+// FindByISRCContext is like FindByISRC, but the requests to Spotify are made with ctx.
+// If ctx is cancelled or its deadline passes before the search is done, a
+// TrackError with ErrorType CanceledError is returned.
+func (s Searcher) FindByISRCContext(ctx context.Context, isrc string) ([]Track, error) {
+	searchQuery, err := constructIsrcSearchQuery(isrc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.collectTracks(ctx, s.searchUrl(searchQuery, maxSearchLimit), 0, 0, IsrcQuery, nil)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return track, nil
+	return result.Tracks, nil
 }
 
-*/
+// collectTracks fetches the page of tracks at url, starting at offset, and the pages
+// following it, until limit tracks playable in the market of the Searcher are found or
+// there are no more pages. A zero limit means that all pages are fetched. It returns the
+// tracks, marked with queryLevel and scored against target unless it is nil, the total
+// number of tracks reported by the API and the offset of the first track not looked at.
+func (s Searcher) collectTracks(ctx context.Context, url string, offset, limit int, queryLevel QueryLevel, target *matchTarget) (FindAllResult, error) {
+	result := FindAllResult{QueryLevel: queryLevel}
+
+	for url != "" {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return FindAllResult{}, ctxErr
+		}
+
+		data, fetchError := s.fetchData(ctx, url)
+
+		if fetchError != nil {
+			return FindAllResult{}, fetchError
+		}
+
+		trackCollection, extractError := extractTrackCollectionFromJSON(data)
+
+		if extractError != nil {
+			return FindAllResult{}, extractError
+		}
+
+		page := trackCollection.Tracks
+		result.Total = page.Total
 
-func constructSearchQuery(title, artist, album string) ([]string, error) {
-	title = strings.TrimSpace(title)
-	artist = strings.TrimSpace(artist)
-	album = strings.TrimSpace(album)
+		for i, candidate := range page.Items {
+			if limit > 0 && len(result.Tracks) == limit {
+				result.NextOffset = offset + i
+
+				return result, nil
+			}
+
+			if s.market != "" && !candidate.isPlayableIn(s.market) {
+				continue
+			}
+
+			track := trackFromItem(candidate)
+			track.QueryLevel = queryLevel
+
+			if target != nil {
+				track.Match = s.matcher().score(track, *target)
+			}
+
+			result.Tracks = append(result.Tracks, track)
+		}
+
+		if len(page.Items) == 0 {
+			break
+		}
+
+		offset += len(page.Items)
+		url = page.Next
+
+		if url != "" {
+			result.NextOffset = offset
+		} else {
+			result.NextOffset = 0
+		}
+
+		if limit > 0 && len(result.Tracks) == limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// constructSearchQuery returns the search queries to try in order for title,
+// artist and album, and the QueryLevel of each of them. The levels are worked
+// out from the same values as the queries, so a name with no words, which is
+// left out of the queries, is left out of the levels too.
+func constructSearchQuery(title, artist, album string) ([]string, []QueryLevel, error) {
+	title = searchValue(title)
+	artist = searchValue(artist)
+	album = searchValue(album)
+
+	var queries []Query
+	var levels []QueryLevel
 
 	if len(title) > 0 {
+		titleQuery := Query{}.Track(title)
+
 		// If both artist and album are supplied, return array of three
 		// search queries so that these can be tried in order if no tracks
 		// are returned.
 		if len(artist) > 0 && len(album) > 0 {
-			return []string{
-				url.QueryEscape(constructSearchQueryFromTitleArtistAndAlbum(title, artist, album)),
-				url.QueryEscape(constructSearchQueryFromTitleAndArtist(title, artist)),
-				url.QueryEscape(constructSearchQueryFromTitleAndAlbum(title, album)),
-			}, nil
+			queries = []Query{titleQuery.Artist(artist).Album(album), titleQuery.Artist(artist), titleQuery.Album(album)}
+			levels = []QueryLevel{TitleArtistAlbumQuery, TitleArtistQuery, TitleAlbumQuery}
 		} else if len(artist) > 0 {
-			return []string{
-				url.QueryEscape(constructSearchQueryFromTitleAndArtist(title, artist)),
-			}, nil
+			queries = []Query{titleQuery.Artist(artist)}
+			levels = []QueryLevel{TitleArtistQuery}
 		} else if len(album) > 0 {
-			return []string{
-				url.QueryEscape(constructSearchQueryFromTitleAndAlbum(title, album)),
-			}, nil
+			queries = []Query{titleQuery.Album(album)}
+			levels = []QueryLevel{TitleAlbumQuery}
 		}
 	}
 
-	return nil, TrackError{Msg: "A title and at least one of article and album must be passed as arguments.", ErrorType: ArgumentError}
+	if len(queries) == 0 {
+		return nil, nil, TrackError{Msg: "A title and at least one of article and album must be passed as arguments.", ErrorType: ArgumentError}
+	}
+
+	searchQueries, err := encodeQueries(queries...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return searchQueries, levels, nil
 }
 
-// TODO Consider skipping the quotes, maybe
-func constructSearchQueryFromTitleAndArtist(title, artist string) string {
-	return fmt.Sprintf("track:\"%s\" artist:\"%s\"", title, artist)
+// normalizeQuery returns title, artist and album normalized by the normalizer
+// of the Searcher, if it has one. A name the normalizer removes entirely, such
+// as a title consisting of a version tag, is kept as it was.
+func (s Searcher) normalizeQuery(title, artist, album string) (string, string, string) {
+	normalize := func(name string) string {
+		if normalized := s.normalizer.Normalize(name); strings.TrimSpace(normalized) != "" {
+			return normalized
+		}
+
+		return name
+	}
+
+	return normalize(title), normalize(artist), normalize(album)
 }
 
-func constructSearchQueryFromTitleAndAlbum(title, album string) string {
-	return fmt.Sprintf("track:\"%s\" album:\"%s\"", title, album)
+// notFoundError returns a TrackError with ErrorType NotFoundError, telling
+// which of the escaped searchQueries were tried.
+func notFoundError(searchQueries []string) error {
+	var queries []string
+
+	for _, searchQuery := range searchQueries {
+		query, err := url.QueryUnescape(searchQuery)
+
+		if err != nil {
+			query = searchQuery
+		}
+
+		queries = append(queries, query)
+	}
+
+	return TrackError{Msg: "No track found searching for " + strings.Join(queries, ", then ") + ".", ErrorType: NotFoundError, Queries: queries}
 }
 
-func constructSearchQueryFromTitleArtistAndAlbum(title, artist, album string) string {
-	return fmt.Sprintf("track:\"%s\" artist:\"%s\" album:\"%s\"", title, artist, album)
+// logger returns the logger of the Searcher. Without a log handler nothing is logged.
+func (s Searcher) logger() *slog.Logger {
+	if s.logHandler == nil {
+		return discardLogger
+	}
+
+	return slog.New(s.logHandler)
 }
 
-func fetchData(url string) ([]byte, error) {
-	resp, httpErr := http.Get(url)
-	defer resp.Body.Close()
+var discardLogger = slog.New(discardHandler{})
 
-	if httpErr != nil {
-		return []byte{}, TrackError{Msg: "Get request failed in fetchData.", ErrorType: UnexpectedError, OriginalError: httpErr}
+// discardHandler is a slog.Handler dropping all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logSearchStep logs that the step:th of the search queries is about to be sent by method.
+func (s Searcher) logSearchStep(ctx context.Context, method string, step int, queryLevel QueryLevel, searchQuery string) {
+	query, err := url.QueryUnescape(searchQuery)
+
+	if err != nil {
+		query = searchQuery
+	}
+
+	s.logger().DebugContext(ctx, "searching", "method", method, "step", step+1, "query_level", queryLevel.String(), "query", query)
+}
+
+// searchUrl returns the url searching for searchQuery, limited to limit
+// results and to the market of the Searcher.
+func (s Searcher) searchUrl(searchQuery string, limit int) string {
+	searchUrl := s.trackSearchBaseUrl + searchQuery + fmt.Sprintf("&limit=%d", limit)
+
+	if s.market != "" {
+		searchUrl += "&market=" + url.QueryEscape(s.market)
+	}
+
+	return searchUrl
+}
+
+// limitOr returns the limit of the Searcher, or defaultLimit if it has none.
+func (s Searcher) limitOr(defaultLimit int) int {
+	if s.limit > 0 {
+		return s.limit
+	}
+
+	return defaultLimit
+}
+
+// constructIsrcSearchQuery returns the search query for tracks with the ISRC isrc.
+func constructIsrcSearchQuery(isrc string) (string, error) {
+	return Query{}.ISRC(isrc).Encode()
+}
+
+func isValidIsrc(isrc string) bool {
+	if len(isrc) != 12 {
+		return false
+	}
+
+	for i, c := range isrc {
+		isLetter := c >= 'A' && c <= 'Z'
+		isDigit := c >= '0' && c <= '9'
+
+		switch {
+		case i < 2 && !isLetter:
+			return false
+		case i >= 2 && i < 5 && !isLetter && !isDigit:
+			return false
+		case i >= 5 && !isDigit:
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodeQueries returns the encoded queries.
+func encodeQueries(queries ...Query) ([]string, error) {
+	var encoded []string
+
+	for _, q := range queries {
+		e, err := q.Encode()
+
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(encoded, e)
+	}
+
+	return encoded, nil
+}
+
+// fetchData returns the body of the response to a GET request for url, or of
+// the cached response if the Searcher has a cache.
+func (s Searcher) fetchData(ctx context.Context, url string) ([]byte, error) {
+	if s.cache != nil {
+		return s.fetchCachedData(ctx, url)
+	}
+
+	resp, err := s.fetchResponse(ctx, url, "")
+
+	return resp.body, err
+}
+
+// response is the part of an http response to a GET request kept by fetchResponse.
+type response struct {
+	body        []byte
+	etag        string
+	notModified bool
+}
+
+// fetchResponse sends a GET request for url, conditional on etag unless it is empty.
+// Requests that are rate limited or fail with a server error are retried until the
+// attempt budget of the Searcher is spent.
+func (s Searcher) fetchResponse(ctx context.Context, url, etag string) (response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := s.fetchResponseOnce(ctx, url, etag)
+
+		if err == nil || attempt >= s.maxAttempts || !isRetryable(err) {
+			return resp, withUrl(err, url)
+		}
+
+		delay := s.retryDelay(attempt, err)
+
+		s.logger().WarnContext(ctx, "retrying request", "url", url, "attempt", attempt, "delay", delay, "error", err)
+
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return response{}, withUrl(sleepErr, url)
+		}
 	}
+}
+
+// withUrl sets the Url of err to url if err is a TrackError without one.
+func withUrl(err error, url string) error {
+	if terr, isTrackError := err.(TrackError); isTrackError && terr.Url == "" {
+		terr.Url = url
+		return terr
+	}
+
+	return err
+}
+
+func (s Searcher) fetchResponseOnce(ctx context.Context, url, etag string) (response, error) {
+	resp, err := s.get(ctx, url, etag)
+
+	if err != nil {
+		return response{}, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.tokens != nil {
+		// The token may have been revoked before it expired, so the request
+		// is tried once more with a new token.
+		resp.Body.Close()
+		s.tokens.invalidate(strings.TrimPrefix(resp.Request.Header.Get("Authorization"), "Bearer "))
+
+		resp, err = s.get(ctx, url, etag)
+
+		if err != nil {
+			return response{}, err
+		}
+	}
+
+	defer resp.Body.Close()
 
 	if !(resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified) {
-		if resp.StatusCode == http.StatusForbidden {
-			return nil, TrackError{Msg: "Rate limit exceeded at Spotify Metadata API.", ErrorType: RateLimitError}
+		spotifyError := extractSpotifyErrorFromJSON(resp.Body)
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+			return response{}, TrackError{Msg: fmt.Sprintf("Rate limit exceeded at Spotify Web API. Retry after %v.", retryAfter), ErrorType: RateLimitError, StatusCode: resp.StatusCode, RetryAfter: retryAfter, SpotifyError: spotifyError}
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			return response{}, TrackError{Msg: "The request was not authorized by the Spotify Web API.", ErrorType: AuthenticationError, StatusCode: resp.StatusCode, SpotifyError: spotifyError}
 		}
 
-		return nil, TrackError{Msg: fmt.Sprintf("GET request in fetchData returned status %d rather than %d or %d", resp.StatusCode, http.StatusOK, http.StatusNotModified), ErrorType: ExternalServiceError}
+		return response{}, TrackError{Msg: fmt.Sprintf("GET request in fetchData returned status %d rather than %d or %d", resp.StatusCode, http.StatusOK, http.StatusNotModified), ErrorType: ExternalServiceError, StatusCode: resp.StatusCode, SpotifyError: spotifyError}
 	}
 
 	body, ioutilErr := ioutil.ReadAll(resp.Body)
 
 	if ioutilErr != nil {
-		return []byte{}, TrackError{Msg: "ioutil.ReadAll failed in fetchData.", ErrorType: UnexpectedError, OriginalError: ioutilErr}
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return response{}, ctxErr
+		}
+
+		return response{}, TrackError{Msg: "ioutil.ReadAll failed in fetchData.", ErrorType: UnexpectedError, OriginalError: ioutilErr}
 	}
 
-	return body, nil
+	return response{body: body, etag: resp.Header.Get("ETag"), notModified: resp.StatusCode == http.StatusNotModified}, nil
 }
 
-func (s Searcher) extractTrackFromJSON(xml_data []byte) (Track, error) {
+// get sends a GET request for url, authorized by a token from the client
+// credentials of the Searcher if it has any. If the Searcher has a rate
+// limiter, get waits for it first. Unless etag is empty the request is
+// conditional on the resource having another ETag.
+func (s Searcher) get(ctx context.Context, url, etag string) (*http.Response, error) {
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if reqErr != nil {
+		return nil, TrackError{Msg: "Unable to create request in fetchData.", ErrorType: UnexpectedError, OriginalError: reqErr}
+	}
+
+	if s.userAgent != "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	if s.tokens != nil {
+		token, tokenErr := s.tokens.token(ctx, s.client)
+
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	resp, httpErr := s.client.Do(req)
+	latency := time.Since(start)
+
+	if httpErr != nil {
+		s.logger().ErrorContext(ctx, "request failed", "url", url, "latency", latency, "error", httpErr)
+
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		return nil, TrackError{Msg: "Get request failed in fetchData.", ErrorType: UnexpectedError, OriginalError: httpErr}
+	}
+
+	level := slog.LevelDebug
+
+	if !(resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified) {
+		level = slog.LevelWarn
+	}
+
+	s.logger().Log(ctx, level, "request", "url", url, "status", resp.StatusCode, "latency", latency)
+
+	return resp, nil
+}
+
+// contextError returns a TrackError with ErrorType CanceledError if ctx is done,
+// and nil otherwise. The OriginalError tells whether ctx was cancelled or its
+// deadline passed.
+func contextError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return TrackError{Msg: "The search was cancelled before it was done.", ErrorType: CanceledError, OriginalError: err}
+	}
+
+	return nil
+}
+
+// extractTrackFromJSON returns the first track in xml_data that is playable in
+// the market of the Searcher and passes the duration filter of fo.
+func (s Searcher) extractTrackFromJSON(xml_data []byte, fo findOptions) (Track, error) {
 	trackCollection, err := extractTrackCollectionFromJSON(xml_data)
 
 	if err != nil {
 		return Track{}, err
 	}
 
-	if len(trackCollection.Tracks.Items) > 0 {
-		trackItem := trackCollection.Tracks.Items[0]
+	items := fo.filter(s.playableItems(trackCollection.Tracks.Items))
 
-		var artists []string
+	if len(items) > 0 {
+		return trackFromItem(items[0]), nil
+	}
 
-		for _, artist := range trackItem.Artists {
-			artists = append(artists, artist.Name)
-		}
+	return Track{}, nil
+}
 
-		track := Track{
-			Name:    trackItem.Name,
-			Uri:     trackItem.Uri,
-			Album:   trackItem.Album.Name,
-			Artists: artists,
+// playableItems returns the items that are playable in the market of the Searcher,
+// in the order they were given.
+func (s Searcher) playableItems(items []item) []item {
+	if s.market == "" {
+		return items
+	}
+
+	var playable []item
+
+	for _, candidate := range items {
+		if candidate.isPlayableIn(s.market) {
+			playable = append(playable, candidate)
 		}
+	}
 
-		return track, nil
+	return playable
+}
+
+func trackFromItem(trackItem item) Track {
+	var artists []Artist
+
+	for _, a := range trackItem.Artists {
+		artists = append(artists, Artist{
+			Id:           a.Id,
+			Name:         a.Name,
+			Uri:          a.Uri,
+			ExternalUrls: a.ExternalUrls,
+		})
 	}
 
-	return Track{}, nil
+	var images []Image
+
+	for _, i := range trackItem.Album.Images {
+		images = append(images, Image{Url: i.Url, Width: i.Width, Height: i.Height})
+	}
+
+	return Track{
+		Id:      trackItem.Id,
+		Name:    trackItem.Name,
+		Uri:     trackItem.Uri,
+		Artists: artists,
+		Album: Album{
+			Id:           trackItem.Album.Id,
+			Name:         trackItem.Album.Name,
+			Uri:          trackItem.Album.Uri,
+			AlbumType:    trackItem.Album.AlbumType,
+			Images:       images,
+			ExternalUrls: trackItem.Album.ExternalUrls,
+		},
+		Duration:         time.Duration(trackItem.DurationMs) * time.Millisecond,
+		Explicit:         trackItem.Explicit,
+		Popularity:       trackItem.Popularity,
+		DiscNumber:       trackItem.DiscNumber,
+		TrackNumber:      trackItem.TrackNumber,
+		Isrc:             trackItem.ExternalIds.Isrc,
+		PreviewUrl:       trackItem.PreviewUrl,
+		ExternalUrls:     trackItem.ExternalUrls,
+		AvailableMarkets: trackItem.AvailableMarkets,
+	}
 }
 
-// trackCollection, trackItem, item, album and artist are structs
-// used for unmarsahlling json data from the Spotify API.
+// trackCollection, trackItem, item, album, artist, image and externalIds
+// are structs used for unmarsahlling json data from the Spotify API.
 type trackCollection struct {
 	Tracks trackItem
 }
 type trackItem struct {
-	Href  string
-	Items []item
+	Href   string
+	Items  []item
+	Limit  int
+	Next   string
+	Offset int
+	Total  int
 }
 type item struct {
-	Uri     string
-	Name    string
-	Album   album
-	Artists []artist
+	Id               string
+	Uri              string
+	Name             string
+	Album            album
+	Artists          []artist
+	AvailableMarkets []string `json:"available_markets"`
+	IsPlayable       *bool    `json:"is_playable"`
+	DurationMs       int      `json:"duration_ms"`
+	Explicit         bool
+	Popularity       int
+	DiscNumber       int               `json:"disc_number"`
+	TrackNumber      int               `json:"track_number"`
+	ExternalIds      externalIds       `json:"external_ids"`
+	PreviewUrl       string            `json:"preview_url"`
+	ExternalUrls     map[string]string `json:"external_urls"`
 }
 type album struct {
-	Name string
+	Id               string
+	Name             string
+	Uri              string
+	AlbumType        string `json:"album_type"`
+	Images           []image
+	AvailableMarkets []string          `json:"available_markets"`
+	ExternalUrls     map[string]string `json:"external_urls"`
 }
 type artist struct {
-	Name string
+	Id           string
+	Name         string
+	Uri          string
+	ExternalUrls map[string]string `json:"external_urls"`
+}
+type image struct {
+	Url    string
+	Width  int
+	Height int
+}
+type externalIds struct {
+	Isrc string
+}
+
+// isPlayableIn tells whether the item can be played in market. When a market is
+// passed to the API it reports is_playable instead of the available markets. Items
+// without markets of their own fall back on those of their album, and an item
+// carrying none of these is assumed to be playable.
+func (i item) isPlayableIn(market string) bool {
+	if i.IsPlayable != nil {
+		return *i.IsPlayable
+	}
+
+	markets := i.AvailableMarkets
+
+	if markets == nil {
+		markets = i.Album.AvailableMarkets
+	}
+
+	if markets == nil {
+		return true
+	}
+
+	for _, m := range markets {
+		if m == market {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spotifyErrorResponse is used for unmarshalling the body of a failed response.
+type spotifyErrorResponse struct {
+	Error *SpotifyError
+}
+
+// maxErrorBodySize is the number of bytes of a failed response read when
+// looking for the error object.
+const maxErrorBodySize = 64 << 10
+
+// extractSpotifyErrorFromJSON returns the error object in the body of a failed
+// response, or nil if the body has none.
+func extractSpotifyErrorFromJSON(body io.Reader) *SpotifyError {
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxErrorBodySize))
+
+	if err != nil {
+		return nil
+	}
+
+	var ser spotifyErrorResponse
+
+	if json.Unmarshal(data, &ser) != nil {
+		return nil
+	}
+
+	return ser.Error
 }
 
 func extractTrackCollectionFromJSON(jsonData []byte) (trackCollection, error) {
@@ -1,6 +1,11 @@
 package track
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -49,3 +54,90 @@ func TestTrackErrorOriginalError(t *testing.T) {
 		t.Errorf("Unexpected error message.\nExpexted: %s\nActual: %s", expectedMessage, actualMessage)
 	}
 }
+
+func TestTrackErrorUnwrap(t *testing.T) {
+	oerr := test_error{msg: "Testing."}
+
+	var err error
+	err = TrackError{Msg: "Testing TrackError.", OriginalError: oerr, ErrorType: UnexpectedError}
+
+	if !errors.Is(err, oerr) {
+		t.Error("Expected errors.Is to find the original error.")
+	}
+
+	var terr test_error
+
+	if !errors.As(err, &terr) || terr.msg != "Testing." {
+		t.Error("Expected errors.As to find the original error.")
+	}
+}
+
+func TestTrackErrorIsSentinel(t *testing.T) {
+	cases := []struct {
+		err      TrackError
+		sentinel error
+	}{
+		{TrackError{ErrorType: ExternalServiceError, StatusCode: http.StatusNotFound}, ErrNotFound},
+		{TrackError{ErrorType: RateLimitError, StatusCode: http.StatusTooManyRequests}, ErrRateLimited},
+		{TrackError{ErrorType: AuthenticationError}, ErrUnauthorized},
+		{TrackError{ErrorType: ArgumentError}, ErrInvalidArgument},
+	}
+
+	sentinels := []error{ErrNotFound, ErrRateLimited, ErrUnauthorized, ErrInvalidArgument}
+
+	for _, c := range cases {
+		for _, sentinel := range sentinels {
+			expected := sentinel == c.sentinel
+			actual := errors.Is(fmt.Errorf("wrapped: %w", c.err), sentinel)
+
+			if expected != actual {
+				t.Errorf("Unexpected result of errors.Is(%#v, %v). Expected: %v, got: %v", c.err, sentinel, expected, actual)
+			}
+		}
+	}
+}
+
+func TestTrackErrorSpotifyErrorMessage(t *testing.T) {
+	err := TrackError{Msg: "Testing TrackError.", ErrorType: ExternalServiceError, SpotifyError: &SpotifyError{Status: 400, Message: "Bad search query"}}
+
+	expectedMessage := errorPrefix + "Testing TrackError. Spotify error: Bad search query"
+	actualMessage := err.Error()
+
+	if expectedMessage != actualMessage {
+		t.Errorf("Unexpected error message.\nExpexted: %s\nActual: %s", expectedMessage, actualMessage)
+	}
+}
+
+func TestFetchDataErrorCarriesResponseDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"status": 404, "message": "Non existing id"}}`))
+	}))
+	defer ts.Close()
+
+	s := newMockSearcher(ts.URL)
+	url := ts.URL + "/tracks/0000000000000000000000"
+
+	_, err := s.fetchData(context.Background(), url)
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error to be ErrNotFound. Got: %v", err)
+	}
+
+	var terr TrackError
+
+	if !errors.As(err, &terr) {
+		t.Fatal("Expected error to be of type TrackError.")
+	}
+
+	if terr.Url != url {
+		t.Errorf("Unexpected url.\nExpected: %s\nActual:   %s", url, terr.Url)
+	}
+
+	expected := SpotifyError{Status: 404, Message: "Non existing id"}
+
+	if terr.SpotifyError == nil || *terr.SpotifyError != expected {
+		t.Errorf("Unexpected Spotify error.\nExpected: %#v\nActual:   %#v", expected, terr.SpotifyError)
+	}
+}
@@ -0,0 +1,299 @@
+package tracktest
+
+import (
+	"strings"
+
+	"github.com/joarleth/spotify/spotifyid"
+)
+
+// pagingObject, trackObject, albumObject, artistObject and imageObject are
+// the json objects of the Spotify API that a Server answers with.
+type pagingObject struct {
+	Href   string        `json:"href"`
+	Items  []trackObject `json:"items"`
+	Limit  int           `json:"limit"`
+	Next   string        `json:"next,omitempty"`
+	Offset int           `json:"offset"`
+	Total  int           `json:"total"`
+}
+type trackObject struct {
+	Id               string            `json:"id"`
+	Uri              string            `json:"uri"`
+	Name             string            `json:"name"`
+	Album            albumObject       `json:"album"`
+	Artists          []artistObject    `json:"artists"`
+	AvailableMarkets []string          `json:"available_markets,omitempty"`
+	IsPlayable       *bool             `json:"is_playable,omitempty"`
+	DurationMs       int               `json:"duration_ms"`
+	Explicit         bool              `json:"explicit"`
+	Popularity       int               `json:"popularity"`
+	DiscNumber       int               `json:"disc_number"`
+	TrackNumber      int               `json:"track_number"`
+	ExternalIds      map[string]string `json:"external_ids"`
+	ExternalUrls     map[string]string `json:"external_urls"`
+	PreviewUrl       *string           `json:"preview_url"`
+	Type             string            `json:"type"`
+}
+type albumObject struct {
+	Id           string            `json:"id"`
+	Uri          string            `json:"uri"`
+	Name         string            `json:"name"`
+	AlbumType    string            `json:"album_type"`
+	ReleaseDate  string            `json:"release_date,omitempty"`
+	Images       []imageObject     `json:"images"`
+	ExternalUrls map[string]string `json:"external_urls"`
+	Tracks       *pagingObject     `json:"tracks,omitempty"`
+	Type         string            `json:"type"`
+}
+type artistObject struct {
+	Id           string            `json:"id"`
+	Uri          string            `json:"uri"`
+	Name         string            `json:"name"`
+	ExternalUrls map[string]string `json:"external_urls"`
+	Type         string            `json:"type"`
+}
+type imageObject struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// newTrackObject returns the json object of t. Like the Spotify API, it
+// tells whether t is playable in market when a market is given, and lists
+// the markets t is available in otherwise.
+func newTrackObject(t Track, market string) trackObject {
+	id := spotifyid.TrackID(t.Id)
+
+	object := trackObject{
+		Id:           t.Id,
+		Uri:          id.URI(),
+		Name:         t.Name,
+		Album:        newAlbumObject(t.Album),
+		Artists:      []artistObject{},
+		DurationMs:   t.DurationMs,
+		Explicit:     t.Explicit,
+		Popularity:   t.Popularity,
+		DiscNumber:   t.DiscNumber,
+		TrackNumber:  t.TrackNumber,
+		ExternalIds:  map[string]string{},
+		ExternalUrls: map[string]string{"spotify": id.URL()},
+		Type:         "track",
+	}
+
+	for _, a := range t.Artists {
+		artistId := spotifyid.ArtistID(a.Id)
+
+		object.Artists = append(object.Artists, artistObject{
+			Id:           a.Id,
+			Uri:          artistId.URI(),
+			Name:         a.Name,
+			ExternalUrls: map[string]string{"spotify": artistId.URL()},
+			Type:         "artist",
+		})
+	}
+
+	if t.Isrc != "" {
+		object.ExternalIds["isrc"] = t.Isrc
+	}
+
+	if t.PreviewUrl != "" {
+		object.PreviewUrl = &t.PreviewUrl
+	}
+
+	if market != "" {
+		playable := isAvailableIn(t, market)
+		object.IsPlayable = &playable
+	} else if t.AvailableMarkets != nil {
+		object.AvailableMarkets = t.AvailableMarkets
+	}
+
+	return object
+}
+
+func newAlbumObject(a Album) albumObject {
+	id := spotifyid.AlbumID(a.Id)
+	albumType := a.AlbumType
+
+	if albumType == "" {
+		albumType = "album"
+	}
+
+	return albumObject{
+		Id:           a.Id,
+		Uri:          id.URI(),
+		Name:         a.Name,
+		AlbumType:    albumType,
+		ReleaseDate:  a.ReleaseDate,
+		Images:       []imageObject{},
+		ExternalUrls: map[string]string{"spotify": id.URL()},
+		Type:         "album",
+	}
+}
+
+func isAvailableIn(t Track, market string) bool {
+	if t.AvailableMarkets == nil {
+		return true
+	}
+
+	for _, m := range t.AvailableMarkets {
+		if strings.EqualFold(m, market) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// searchTerm is a part of a search query: a field filter such as
+// track:"Human Behaviour", or a word not belonging to any field when field is
+// empty. A negated term was preceded by NOT, and matches the tracks the term
+// does not match.
+type searchTerm struct {
+	field   string
+	value   string
+	negated bool
+}
+
+// searchTerms are the parts of a search query. A track matches when it matches
+// at least one term of every clause. Terms joined by OR share a clause, and
+// every other term has a clause of its own.
+type searchTerms struct {
+	clauses [][]searchTerm
+}
+
+// parseSearchQuery splits q into field filters and words, and the NOT and OR
+// operators combining them. A value is either a single word or a phrase in
+// double quotes, which ends at the next quote. Like Spotify, OR binds the
+// terms next to it, so "a b OR c" matches tracks matching a and either b or c.
+func parseSearchQuery(q string) searchTerms {
+	var terms searchTerms
+
+	rest := strings.TrimSpace(q)
+	negated, or := false, false
+
+	for rest != "" {
+		field := ""
+
+		if i := strings.IndexAny(rest, ": \""); i > 0 && rest[i] == ':' {
+			field = strings.ToLower(rest[:i])
+			rest = rest[i+1:]
+		}
+
+		quoted := strings.HasPrefix(rest, `"`)
+
+		var value string
+		value, rest = readValue(rest)
+		rest = strings.TrimSpace(rest)
+
+		switch {
+		case field == "" && !quoted && value == "NOT":
+			negated = true
+			continue
+		case field == "" && !quoted && value == "OR":
+			or = len(terms.clauses) > 0
+			continue
+		case field == "" && value == "":
+			continue
+		}
+
+		term := searchTerm{field: field, value: value, negated: negated}
+
+		if or {
+			last := len(terms.clauses) - 1
+			terms.clauses[last] = append(terms.clauses[last], term)
+		} else {
+			terms.clauses = append(terms.clauses, []searchTerm{term})
+		}
+
+		negated, or = false, false
+	}
+
+	return terms
+}
+
+// readValue returns the value at the start of s, and what follows it.
+func readValue(s string) (value, rest string) {
+	if !strings.HasPrefix(s, `"`) {
+		if i := strings.IndexByte(s, ' '); i >= 0 {
+			return s[:i], s[i:]
+		}
+
+		return s, ""
+	}
+
+	if value, rest, found := strings.Cut(s[1:], `"`); found {
+		return value, rest
+	}
+
+	return s[1:], ""
+}
+
+// match tells whether t matches the terms.
+func (st searchTerms) match(t Track) bool {
+	for _, clause := range st.clauses {
+		matched := false
+
+		for _, term := range clause {
+			if term.match(t) != term.negated {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match tells whether t matches the term, ignoring whether it is negated.
+// Names match a value containing them when case is ignored, and fields not
+// known to a Server match every track.
+func (term searchTerm) match(t Track) bool {
+	var artists []string
+
+	for _, a := range t.Artists {
+		artists = append(artists, a.Name)
+	}
+
+	switch term.field {
+	case "":
+		return contains(strings.Join(append([]string{t.Name, t.Album.Name}, artists...), "\n"), term.value)
+	case "track":
+		return contains(t.Name, term.value)
+	case "artist":
+		return contains(strings.Join(artists, "\n"), term.value)
+	case "album":
+		return contains(t.Album.Name, term.value)
+	case "isrc":
+		return strings.EqualFold(t.Isrc, term.value)
+	case "year":
+		return inYears(t.Album.ReleaseDate, term.value)
+	}
+
+	return true
+}
+
+// inYears tells whether releaseDate is in years, either a single year such
+// as "1993" or a range such as "1990-1999".
+func inYears(releaseDate, years string) bool {
+	if len(releaseDate) < 4 {
+		return false
+	}
+
+	first, last, isRange := strings.Cut(years, "-")
+
+	if !isRange {
+		last = first
+	}
+
+	year := releaseDate[:4]
+
+	return year >= first && year <= last
+}
+
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
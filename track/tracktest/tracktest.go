@@ -0,0 +1,477 @@
+// Package tracktest provides a fake Spotify Web API server for testing code
+// using the track package without reaching Spotify.
+//
+// A Server serves track searches, tracks, albums and client credentials access
+// tokens from a catalogue of tracks given to it, and records the requests it
+// receives. It can be made to answer with errors such as 401, 429 and 5xx
+// statuses, and to respond slowly. Searches understand the track, artist,
+// album, isrc and year filters and the NOT and OR operators.
+//
+//	server := tracktest.NewServer(tracktest.Track{
+//		Id:      "4ry6oqlwdsooYtniYJFkt5",
+//		Name:    "Human Behaviour",
+//		Artists: []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Björk"}},
+//		Album:   tracktest.Album{Id: "3icOCBqUqz5JJl9ajZzBwf", Name: "Debut"},
+//	})
+//	defer server.Close()
+//
+//	s := track.NewSearcher(track.WithBaseURL(server.URL))
+package tracktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The client credentials the token endpoint of a Server accepts.
+const (
+	ClientId     = "tracktest-client"
+	ClientSecret = "tracktest-secret"
+)
+
+// TokenPath is the path of the token endpoint of a Server.
+const TokenPath = "/api/token"
+
+// tokenLifetime is how long the access tokens handed out by a Server are valid.
+const tokenLifetime = time.Hour
+
+// Track is a track in the catalogue of a Server. Tracks without
+// AvailableMarkets are playable in every market.
+type Track struct {
+	Id               string
+	Name             string
+	Artists          []Artist
+	Album            Album
+	DurationMs       int
+	Explicit         bool
+	Popularity       int
+	DiscNumber       int
+	TrackNumber      int
+	Isrc             string
+	PreviewUrl       string
+	AvailableMarkets []string
+}
+
+// Artist is an artist of a Track.
+type Artist struct {
+	Id   string
+	Name string
+}
+
+// Album is the album of a Track. The tracks of an album are the tracks in
+// the catalogue with an album of the same Id.
+type Album struct {
+	Id          string
+	Name        string
+	AlbumType   string
+	ReleaseDate string
+}
+
+// Fault makes a Server fail requests rather than answer them.
+// Status is the status the requests are answered with, and RetryAfter the
+// value of the Retry-After header, if any. Count is the number of requests
+// failed, one if it is zero, and a negative Count fails every request. Only
+// requests with a path starting with Path are failed, and an empty Path
+// matches every request except those for tokens.
+type Fault struct {
+	Status     int
+	RetryAfter time.Duration
+	Count      int
+	Path       string
+}
+
+// Request is a request received by a Server, with the Status it was
+// answered with.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Status int
+}
+
+// Server is a fake Spotify Web API. Its URL is passed to track.WithBaseURL,
+// and the url returned by TokenURL to track.WithTokenURL.
+// The methods of a Server are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	tracks       []Track
+	faults       []Fault
+	latency      time.Duration
+	requireToken bool
+	tokens       map[string]bool
+	issued       int
+	requests     []Request
+}
+
+// NewServer starts and returns a Server with tracks in its catalogue.
+// The caller should call Close when done with it.
+func NewServer(tracks ...Track) *Server {
+	s := &Server{tracks: tracks, tokens: make(map[string]bool)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(TokenPath, s.serveToken)
+	mux.HandleFunc("/search", s.serveSearch)
+	mux.HandleFunc("/search/", s.serveSearch)
+	mux.HandleFunc("/tracks", s.serveTracks)
+	mux.HandleFunc("/tracks/", s.serveTrack)
+	mux.HandleFunc("/albums/", s.serveAlbum)
+
+	s.Server = httptest.NewServer(s.intercept(mux))
+
+	return s
+}
+
+// TokenURL returns the url of the token endpoint of the Server.
+func (s *Server) TokenURL() string {
+	return s.URL + TokenPath
+}
+
+// Add adds tracks to the catalogue of the Server.
+func (s *Server) Add(tracks ...Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracks = append(s.tracks, tracks...)
+}
+
+// RequireToken makes the Server answer requests without a valid access token
+// from its token endpoint with status 401.
+func (s *Server) RequireToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requireToken = true
+}
+
+// ExpireTokens makes the access tokens handed out so far invalid, as if they
+// had expired.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]bool)
+}
+
+// Fail adds a fault to the Server. Faults are applied in the order they are
+// added, and a request is failed by the first fault matching it.
+func (s *Server) Fail(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Count == 0 {
+		f.Count = 1
+	}
+
+	s.faults = append(s.faults, f)
+}
+
+// SetLatency makes the Server wait for latency before answering every request.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// Requests returns the requests the Server has received so far, in the order
+// they were answered.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// intercept records the requests to next, and applies the latency, the
+// faults and the token requirement of the Server before passing them on.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.requests = append(s.requests, Request{
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Query:  r.URL.Query(),
+				Header: r.Header.Clone(),
+				Status: rw.status,
+			})
+		}()
+
+		s.mu.Lock()
+		latency := s.latency
+		fault, faulty := s.takeFault(r.URL.Path)
+		unauthorized := s.requireToken && r.URL.Path != TokenPath && !s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		switch {
+		case faulty:
+			if fault.RetryAfter > 0 {
+				rw.Header().Set("Retry-After", strconv.Itoa(int((fault.RetryAfter+time.Second-1)/time.Second)))
+			}
+
+			writeError(rw, fault.Status, http.StatusText(fault.Status))
+		case unauthorized:
+			writeError(rw, http.StatusUnauthorized, "Invalid access token")
+		default:
+			next.ServeHTTP(rw, r)
+		}
+	})
+}
+
+// takeFault returns the first fault matching path, counting the request
+// against it. It must be called with s.mu held.
+func (s *Server) takeFault(path string) (Fault, bool) {
+	for i, f := range s.faults {
+		if f.Path == "" && path == TokenPath || !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+
+		if f.Count > 0 {
+			s.faults[i].Count--
+		}
+
+		if s.faults[i].Count == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+
+		return f, true
+	}
+
+	return Fault{}, false
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+
+	if !ok || clientId != ClientId || clientSecret != ClientSecret || r.PostFormValue("grant_type") != "client_credentials" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid_client"}`))
+		return
+	}
+
+	s.mu.Lock()
+	s.issued++
+	token := fmt.Sprintf("tracktest-token-%d", s.issued)
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenLifetime / time.Second),
+	})
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("type") != "track" {
+		writeError(w, http.StatusBadRequest, "Only searching for tracks is supported")
+		return
+	}
+
+	if strings.TrimSpace(query.Get("q")) == "" {
+		writeError(w, http.StatusBadRequest, "No search query")
+		return
+	}
+
+	limit, offset, ok := paging(query)
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid limit or offset")
+		return
+	}
+
+	terms := parseSearchQuery(query.Get("q"))
+	market := query.Get("market")
+
+	var matches []Track
+
+	for _, t := range s.catalogue() {
+		if terms.match(t) {
+			matches = append(matches, t)
+		}
+	}
+
+	end := min(offset+limit, len(matches))
+	items := []trackObject{}
+
+	for _, t := range matches[min(offset, end):end] {
+		items = append(items, newTrackObject(t, market))
+	}
+
+	page := pagingObject{Href: s.URL + r.URL.String(), Items: items, Limit: limit, Offset: offset, Total: len(matches)}
+
+	if end < len(matches) {
+		next := *r.URL
+		q := next.Query()
+		q.Set("offset", strconv.Itoa(end))
+		q.Set("limit", strconv.Itoa(limit))
+		next.RawQuery = q.Encode()
+		page.Next = s.URL + next.String()
+	}
+
+	writeJSON(w, map[string]interface{}{"tracks": page})
+}
+
+func (s *Server) serveTracks(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+
+	if len(ids) > 50 || ids[0] == "" {
+		writeError(w, http.StatusBadRequest, "Invalid ids")
+		return
+	}
+
+	market := r.URL.Query().Get("market")
+	tracks := make([]*trackObject, len(ids))
+
+	for i, id := range ids {
+		if t, found := s.track(id); found {
+			object := newTrackObject(t, market)
+			tracks[i] = &object
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"tracks": tracks})
+}
+
+func (s *Server) serveTrack(w http.ResponseWriter, r *http.Request) {
+	t, found := s.track(strings.TrimPrefix(r.URL.Path, "/tracks/"))
+
+	if !found {
+		writeError(w, http.StatusNotFound, "Non existing id")
+		return
+	}
+
+	writeJSON(w, newTrackObject(t, r.URL.Query().Get("market")))
+}
+
+func (s *Server) serveAlbum(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/albums/")
+	market := r.URL.Query().Get("market")
+
+	var found *albumObject
+	items := []trackObject{}
+
+	for _, t := range s.catalogue() {
+		if t.Album.Id != id {
+			continue
+		}
+
+		if found == nil {
+			object := newAlbumObject(t.Album)
+			found = &object
+		}
+
+		items = append(items, newTrackObject(t, market))
+	}
+
+	if found == nil {
+		writeError(w, http.StatusNotFound, "Non existing id")
+		return
+	}
+
+	found.Tracks = &pagingObject{Items: items, Limit: len(items), Total: len(items)}
+
+	writeJSON(w, found)
+}
+
+// catalogue returns a copy of the tracks of the Server.
+func (s *Server) catalogue() []Track {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Track(nil), s.tracks...)
+}
+
+// track returns the track with the id id from the catalogue.
+func (s *Server) track(id string) (Track, bool) {
+	for _, t := range s.catalogue() {
+		if t.Id == id {
+			return t, true
+		}
+	}
+
+	return Track{}, false
+}
+
+// paging returns the limit and offset parameters of query, which default to
+// 20 and 0 like in the Spotify API.
+func paging(query url.Values) (limit, offset int, ok bool) {
+	limit, offset = 20, 0
+
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+
+		if err != nil || n < 1 || n > 50 {
+			return 0, 0, false
+		}
+
+		limit = n
+	}
+
+	if o := query.Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+
+		if err != nil || n < 0 {
+			return 0, 0, false
+		}
+
+		offset = n
+	}
+
+	return limit, offset, true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with status and an error object like the ones of the
+// Spotify API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"status": status, "message": message},
+	})
+}
@@ -0,0 +1,211 @@
+package tracktest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joarleth/spotify/spotifyid"
+	"github.com/joarleth/spotify/track"
+	"github.com/joarleth/spotify/track/tracktest"
+)
+
+var debut = tracktest.Album{Id: "3icOCBqUqz5JJl9ajZzBwf", Name: "Debut", ReleaseDate: "1993-07-05"}
+
+var bjork = []tracktest.Artist{{Id: "7w29UYBi0qsHi5RTcv3lmA", Name: "Björk"}}
+
+var catalogue = []tracktest.Track{
+	{Id: "4ry6oqlwdsooYtniYJFkt5", Name: "Human Behaviour", Artists: bjork, Album: debut, DurationMs: 252000, TrackNumber: 1, Isrc: "GBBTF9300001", AvailableMarkets: []string{"SE"}},
+	{Id: "6scvoA7nOKC5EZCi31R6WW", Name: "Human Behaviour", Artists: bjork, Album: debut, DurationMs: 252000, TrackNumber: 1, Isrc: "GBBTF9300001"},
+	{Id: "1k7OSBStzqXBKmFHFUhFoT", Name: "Venus as a Boy", Artists: bjork, Album: debut, DurationMs: 281000, TrackNumber: 3},
+}
+
+func TestSearch(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL), track.WithMarket("US"))
+
+	found, err := s.Find("human behaviour", "Björk", "Debut")
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if found.Uri != "spotify:track:6scvoA7nOKC5EZCi31R6WW" || found.Duration != 252*time.Second || found.Isrc != "GBBTF9300001" {
+		t.Errorf("Unexpected track: %v", found)
+	}
+
+	if _, err := s.Find("Army of Me", "Björk", ""); !errors.Is(err, track.ErrNotFound) {
+		t.Errorf("Expected error to be ErrNotFound. Got: %v", err)
+	}
+
+	requests := server.Requests()
+
+	if len(requests) != 2 || requests[0].Path != "/search/" || requests[0].Query.Get("market") != "US" {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL))
+
+	result, err := s.FindAll("a", "Björk", "", track.FindAllOptions{Limit: 3})
+
+	if err != nil || result.Total != 3 || len(result.Tracks) != 3 {
+		t.Errorf("Unexpected result of FindAll: %d tracks of %d, %v", len(result.Tracks), result.Total, err)
+	}
+
+	byIsrc, err := s.FindByISRC("GBBTF9300001")
+
+	if err != nil || len(byIsrc) != 2 {
+		t.Errorf("Unexpected result of FindByISRC: %v, %v", byIsrc, err)
+	}
+}
+
+func TestTracks(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL))
+
+	tracks, err := s.GetTracks([]spotifyid.TrackID{"1k7OSBStzqXBKmFHFUhFoT", "0000000000000000000000"})
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	if tracks[0].Name != "Venus as a Boy" || tracks[1].Uri != "" {
+		t.Errorf("Unexpected tracks: %v", tracks)
+	}
+}
+
+func TestAlbum(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/albums/" + debut.Id)
+
+	if err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	var album struct {
+		Name   string
+		Tracks struct {
+			Items []struct{ Name string }
+		}
+	}
+
+	json.NewDecoder(resp.Body).Decode(&album)
+
+	if album.Name != "Debut" || len(album.Tracks.Items) != 3 {
+		t.Errorf("Unexpected album: %v", album)
+	}
+
+	resp, _ = http.Get(server.URL + "/albums/0000000000000000000000")
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d. Got: %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	server.RequireToken()
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL))
+
+	if _, err := s.Find("Venus as a Boy", "Björk", ""); !errors.Is(err, track.ErrUnauthorized) {
+		t.Errorf("Expected error to be ErrUnauthorized. Got: %v", err)
+	}
+
+	s = track.NewSearcher(
+		track.WithBaseURL(server.URL),
+		track.WithTokenURL(server.TokenURL()),
+		track.WithClientCredentials(tracktest.ClientId, tracktest.ClientSecret),
+	)
+
+	if _, err := s.Find("Venus as a Boy", "Björk", ""); err != nil {
+		t.Fatalf("Expected error to be nil. Got: %s", err.Error())
+	}
+
+	server.ExpireTokens()
+
+	if _, err := s.Find("Venus as a Boy", "Björk", ""); err != nil {
+		t.Errorf("Expected a new token to be fetched. Got: %s", err.Error())
+	}
+
+	var tokenRequests int
+
+	for _, r := range server.Requests() {
+		if r.Path == tracktest.TokenPath {
+			tokenRequests++
+		}
+	}
+
+	if tokenRequests != 2 {
+		t.Errorf("Expected 2 token requests. Got: %d", tokenRequests)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	server.Fail(tracktest.Fault{Status: http.StatusTooManyRequests, RetryAfter: time.Second})
+	server.Fail(tracktest.Fault{Status: http.StatusBadGateway, Path: "/search"})
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL))
+
+	_, err := s.Find("Venus as a Boy", "Björk", "")
+
+	var terr track.TrackError
+
+	if !errors.As(err, &terr) || terr.ErrorType != track.RateLimitError || terr.RetryAfter != time.Second {
+		t.Errorf("Expected a RateLimitError retrying after a second. Got: %v", err)
+	}
+
+	if _, err := s.Find("Venus as a Boy", "Björk", ""); !errors.As(err, &terr) || terr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected status %d. Got: %v", http.StatusBadGateway, err)
+	}
+
+	if _, err := s.Find("Venus as a Boy", "Björk", ""); err != nil {
+		t.Errorf("Expected the faults to be used up. Got: %s", err.Error())
+	}
+
+	statuses := []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}
+
+	for i, r := range server.Requests() {
+		if r.Status != statuses[i] {
+			t.Errorf("Unexpected status of request %d. Expected: %d, got: %d", i, statuses[i], r.Status)
+		}
+	}
+}
+
+func TestLatency(t *testing.T) {
+	server := tracktest.NewServer(catalogue...)
+	defer server.Close()
+
+	server.SetLatency(time.Second)
+
+	s := track.NewSearcher(track.WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := s.FindContext(ctx, "Venus as a Boy", "Björk", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to be context.DeadlineExceeded. Got: %v", err)
+	}
+}